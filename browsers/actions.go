@@ -0,0 +1,472 @@
+package browsers
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Special keys for Actions, encoded as the code points defined by the W3C WebDriver specification.
+// Printable characters are passed as themselves, such as "a" or "!".
+const (
+	KeyBackspace  = "\ue003"
+	KeyTab        = "\ue004"
+	KeyEnter      = "\ue007"
+	KeyShift      = "\ue008"
+	KeyControl    = "\ue009"
+	KeyAlt        = "\ue00a"
+	KeyEscape     = "\ue00c"
+	KeyPageUp     = "\ue00e"
+	KeyPageDown   = "\ue00f"
+	KeyEnd        = "\ue010"
+	KeyHome       = "\ue011"
+	KeyArrowLeft  = "\ue012"
+	KeyArrowUp    = "\ue013"
+	KeyArrowRight = "\ue014"
+	KeyArrowDown  = "\ue015"
+	KeyInsert     = "\ue016"
+	KeyDelete     = "\ue017"
+	KeyF1         = "\ue031"
+	KeyF2         = "\ue032"
+	KeyF3         = "\ue033"
+	KeyF4         = "\ue034"
+	KeyF5         = "\ue035"
+	KeyF6         = "\ue036"
+	KeyF7         = "\ue037"
+	KeyF8         = "\ue038"
+	KeyF9         = "\ue039"
+	KeyF10        = "\ue03a"
+	KeyF11        = "\ue03b"
+	KeyF12        = "\ue03c"
+	KeyMeta       = "\ue03d"
+)
+
+// chromeKeys maps the special keys onto Rod's key symbols
+var chromeKeys = map[string]input.Key{
+	KeyBackspace:  input.Backspace,
+	KeyTab:        input.Tab,
+	KeyEnter:      input.Enter,
+	KeyShift:      input.ShiftLeft,
+	KeyControl:    input.ControlLeft,
+	KeyAlt:        input.AltLeft,
+	KeyEscape:     input.Escape,
+	KeyPageUp:     input.PageUp,
+	KeyPageDown:   input.PageDown,
+	KeyEnd:        input.End,
+	KeyHome:       input.Home,
+	KeyArrowLeft:  input.ArrowLeft,
+	KeyArrowUp:    input.ArrowUp,
+	KeyArrowRight: input.ArrowRight,
+	KeyArrowDown:  input.ArrowDown,
+	KeyInsert:     input.Insert,
+	KeyDelete:     input.Delete,
+	KeyF1:         input.F1,
+	KeyF2:         input.F2,
+	KeyF3:         input.F3,
+	KeyF4:         input.F4,
+	KeyF5:         input.F5,
+	KeyF6:         input.F6,
+	KeyF7:         input.F7,
+	KeyF8:         input.F8,
+	KeyF9:         input.F9,
+	KeyF10:        input.F10,
+	KeyF11:        input.F11,
+	KeyF12:        input.F12,
+	KeyMeta:       input.MetaLeft,
+}
+
+// MouseButton is a pointer button, numbered as in the W3C WebDriver specification
+type MouseButton int
+
+// Mouse buttons
+const (
+	MouseLeft   MouseButton = 0
+	MouseMiddle MouseButton = 1
+	MouseRight  MouseButton = 2
+)
+
+// chromeButtons maps the mouse buttons onto CDP button names
+var chromeButtons = map[MouseButton]proto.InputMouseButton{
+	MouseLeft:   proto.InputMouseButtonLeft,
+	MouseMiddle: proto.InputMouseButtonMiddle,
+	MouseRight:  proto.InputMouseButtonRight,
+}
+
+// Actions is a sequence of keyboard, mouse and wheel input performed with Browser.Perform.
+// Build it with chained calls, for example NewActions().KeyDown(KeyShift).Press("a").KeyUp(KeyShift).
+type Actions struct {
+	steps []inputStep
+}
+
+// inputStep is one action of the sequence, its type is one of the webdriver.Action constants
+type inputStep struct {
+	kind     string
+	key      string
+	button   MouseButton
+	target   Element // element the pointer or wheel is positioned on, nil for the viewport
+	relative bool    // pointer moves by x and y from its current position
+	x, y     float64
+	dx, dy   float64
+	duration time.Duration
+}
+
+// NewActions starts an empty input sequence
+func NewActions() *Actions {
+	return &Actions{}
+}
+
+func (a *Actions) add(step inputStep) *Actions {
+	a.steps = append(a.steps, step)
+	return a
+}
+
+// KeyDown presses and holds the key
+func (a *Actions) KeyDown(key string) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionKeyDown, key: key})
+}
+
+// KeyUp releases the key
+func (a *Actions) KeyUp(key string) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionKeyUp, key: key})
+}
+
+// Press presses and releases each key in turn
+func (a *Actions) Press(keys ...string) *Actions {
+	for _, key := range keys {
+		a.KeyDown(key).KeyUp(key)
+	}
+	return a
+}
+
+// Type presses and releases the key of every character of the text
+func (a *Actions) Type(text string) *Actions {
+	for _, r := range text {
+		a.Press(string(r))
+	}
+	return a
+}
+
+// MoveTo moves the pointer to the center of the element
+func (a *Actions) MoveTo(el Element) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionPointerMove, target: el})
+}
+
+// MoveToPoint moves the pointer to the viewport coordinates
+func (a *Actions) MoveToPoint(x, y float64) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionPointerMove, x: x, y: y})
+}
+
+// MoveBy moves the pointer by the offset from its current position
+func (a *Actions) MoveBy(dx, dy float64) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionPointerMove, relative: true, x: dx, y: dy})
+}
+
+// Down presses and holds the mouse button
+func (a *Actions) Down(button MouseButton) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionPointerDown, button: button})
+}
+
+// Up releases the mouse button
+func (a *Actions) Up(button MouseButton) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionPointerUp, button: button})
+}
+
+// Click presses and releases the mouse button at the pointer position
+func (a *Actions) Click(button MouseButton) *Actions {
+	return a.Down(button).Up(button)
+}
+
+// DoubleClick clicks the left button twice at the pointer position
+func (a *Actions) DoubleClick() *Actions {
+	return a.Click(MouseLeft).Click(MouseLeft)
+}
+
+// Hover moves the pointer over the element
+func (a *Actions) Hover(el Element) *Actions {
+	return a.MoveTo(el)
+}
+
+// ContextClick right-clicks the center of the element
+func (a *Actions) ContextClick(el Element) *Actions {
+	return a.MoveTo(el).Click(MouseRight)
+}
+
+// DragAndDrop drags the source element onto the center of the target element
+func (a *Actions) DragAndDrop(source, target Element) *Actions {
+	return a.MoveTo(source).Down(MouseLeft).MoveTo(target).Up(MouseLeft)
+}
+
+// Wheel scrolls by the delta with the wheel over the center of the element,
+// or over the top-left corner of the viewport when el is nil
+func (a *Actions) Wheel(el Element, dx, dy float64) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionScroll, target: el, dx: dx, dy: dy})
+}
+
+// Pause waits before the next action
+func (a *Actions) Pause(d time.Duration) *Actions {
+	return a.add(inputStep{kind: webdriver.ActionPause, duration: d})
+}
+
+// Perform runs the actions on the current page, releasing all held input if one of them fails
+func (c *Chrome) Perform(actions *Actions) error {
+	if c.Page == nil {
+		return fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	c.input.clicks = 0
+	// Perform takes no context of its own, pauses end early when the page's is done
+	ctx := c.Page.GetContext()
+	for _, step := range actions.steps {
+		if err := c.perform(ctx, step); err != nil {
+			c.ReleaseActions()
+			return fmt.Errorf("failed to perform %s: %w", step.kind, err)
+		}
+	}
+	return nil
+}
+
+func (c *Chrome) perform(ctx context.Context, step inputStep) error {
+	page := c.Page
+	if c.input.keys == nil {
+		c.input.keys = map[input.Key]bool{}
+		c.input.buttons = map[proto.InputMouseButton]bool{}
+	}
+	switch step.kind {
+	case webdriver.ActionKeyDown, webdriver.ActionKeyUp:
+		key, ok := chromeKey(step.key)
+		if !ok {
+			if step.kind == webdriver.ActionKeyDown {
+				// Characters missing from the keyboard layout can only be inserted
+				return chromeError(page.InsertText(step.key))
+			}
+			return nil
+		}
+		if step.kind == webdriver.ActionKeyDown {
+			c.input.keys[key] = true
+			return chromeError(page.Keyboard.Press(key))
+		}
+		delete(c.input.keys, key)
+		return chromeError(page.Keyboard.Release(key))
+
+	case webdriver.ActionPointerMove:
+		point := proto.Point{X: step.x, Y: step.y}
+		if step.relative {
+			point = page.Mouse.Position().Add(point)
+		}
+		if step.target != nil {
+			center, err := chromeCenter(step.target)
+			if err != nil {
+				return err
+			}
+			point = center
+		}
+		c.input.clicks = 0
+		return chromeError(page.Mouse.MoveTo(point))
+
+	case webdriver.ActionPointerDown, webdriver.ActionPointerUp:
+		button, ok := chromeButtons[step.button]
+		if !ok {
+			return fmt.Errorf("unknown mouse button %d: %w", step.button, ErrInvalidArgument)
+		}
+		if step.kind == webdriver.ActionPointerDown {
+			// Presses in the same place count as one multi-click, as they do for a real mouse
+			if c.input.lastButton != button {
+				c.input.clicks = 0
+			}
+			c.input.clicks++
+			c.input.lastButton = button
+			c.input.buttons[button] = true
+			return chromeError(page.Mouse.Down(button, c.input.clicks))
+		}
+		delete(c.input.buttons, button)
+		return chromeError(page.Mouse.Up(button, c.input.clicks))
+
+	case webdriver.ActionScroll:
+		var point proto.Point
+		if step.target != nil {
+			center, err := chromeCenter(step.target)
+			if err != nil {
+				return err
+			}
+			point = center
+		}
+		return chromeError(proto.InputDispatchMouseEvent{
+			Type:   proto.InputDispatchMouseEventTypeMouseWheel,
+			X:      point.X,
+			Y:      point.Y,
+			DeltaX: step.dx,
+			DeltaY: step.dy,
+		}.Call(page))
+
+	case webdriver.ActionPause:
+		c.input.clicks = 0
+		select {
+		case <-time.After(step.duration):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// ReleaseActions releases all keys and mouse buttons held by earlier actions.
+// Switching or closing pages and closing the browser release them too.
+func (c *Chrome) ReleaseActions() error {
+	if c.Page == nil {
+		c.input = chromeInput{}
+		return nil
+	}
+	var firstErr error
+	for key := range c.input.keys {
+		if err := c.Page.Keyboard.Release(key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for button := range c.input.buttons {
+		if err := c.Page.Mouse.Up(button, 1); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	c.input = chromeInput{}
+	if firstErr != nil {
+		return fmt.Errorf("failed to release actions: %w", chromeError(firstErr))
+	}
+	return nil
+}
+
+// chromeInput tracks the input held by actions so it can be released
+type chromeInput struct {
+	keys       map[input.Key]bool
+	buttons    map[proto.InputMouseButton]bool
+	lastButton proto.InputMouseButton
+	clicks     int
+}
+
+// chromeKey returns the Rod key for a special key or a character of the US keyboard layout
+func chromeKey(key string) (input.Key, bool) {
+	if k, ok := chromeKeys[key]; ok {
+		return k, true
+	}
+	r, size := utf8.DecodeRuneInString(key)
+	if size != len(key) || r < ' ' || r > '~' {
+		return 0, false
+	}
+	return input.Key(r), true
+}
+
+// chromeCenter scrolls the element into view and returns its center in viewport coordinates
+func chromeCenter(el Element) (proto.Point, error) {
+	e, ok := unwrapElement(el).(*chromeElement)
+	if !ok {
+		return proto.Point{}, fmt.Errorf("element does not belong to Chrome: %w", ErrInvalidArgument)
+	}
+	if err := e.el.ScrollIntoView(); err != nil {
+		return proto.Point{}, chromeError(err)
+	}
+	shape, err := e.el.Shape()
+	if err != nil {
+		return proto.Point{}, chromeError(err)
+	}
+	point := shape.OnePointInside()
+	if point == nil {
+		return proto.Point{}, fmt.Errorf("element has no visible area: %w", ErrElementNotInteractable)
+	}
+	return *point, nil
+}
+
+// Perform runs the actions in the current window, releasing all held input if they fail
+func (b *webDriverBrowser) Perform(actions *Actions) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	sequences, err := webDriverActions(actions)
+	if err != nil {
+		return err
+	}
+	if err := b.session.PerformActions(ctx, sequences); err != nil {
+		b.session.ReleaseActions(ctx)
+		return fmt.Errorf("failed to perform actions: %w", err)
+	}
+	return nil
+}
+
+// ReleaseActions releases all keys and mouse buttons held by earlier actions.
+// Closing a page or the browser releases them too.
+func (b *webDriverBrowser) ReleaseActions() error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if err := b.session.ReleaseActions(context.Background()); err != nil {
+		return fmt.Errorf("failed to release actions: %w", err)
+	}
+	return nil
+}
+
+// webDriverActions compiles the actions into one key, pointer and wheel sequence ticking in lockstep
+func webDriverActions(actions *Actions) ([]webdriver.ActionSequence, error) {
+	keys := webdriver.ActionSequence{Type: webdriver.SourceKey, ID: "keyboard", Actions: []webdriver.Action{}}
+	pointer := webdriver.ActionSequence{
+		Type:       webdriver.SourcePointer,
+		ID:         "mouse",
+		Parameters: &webdriver.PointerParameters{PointerType: "mouse"},
+		Actions:    []webdriver.Action{},
+	}
+	wheel := webdriver.ActionSequence{Type: webdriver.SourceWheel, ID: "wheel", Actions: []webdriver.Action{}}
+
+	for _, step := range actions.steps {
+		pause := webdriver.Action{Type: webdriver.ActionPause}
+		keyAction, pointerAction, wheelAction := pause, pause, pause
+
+		switch step.kind {
+		case webdriver.ActionKeyDown, webdriver.ActionKeyUp:
+			if utf8.RuneCountInString(step.key) != 1 {
+				return nil, fmt.Errorf("key %q is not a single character: %w", step.key, ErrInvalidArgument)
+			}
+			keyAction = webdriver.Action{Type: step.kind, Value: step.key}
+		case webdriver.ActionPointerMove:
+			pointerAction = webdriver.Action{Type: step.kind, X: step.x, Y: step.y, Origin: "viewport"}
+			if step.relative {
+				pointerAction.Origin = "pointer"
+			}
+			if step.target != nil {
+				el, err := webDriverTarget(step.target)
+				if err != nil {
+					return nil, err
+				}
+				pointerAction.Origin = el
+			}
+		case webdriver.ActionPointerDown, webdriver.ActionPointerUp:
+			pointerAction = webdriver.Action{Type: step.kind, Button: int(step.button)}
+		case webdriver.ActionScroll:
+			wheelAction = webdriver.Action{Type: step.kind, DeltaX: step.dx, DeltaY: step.dy, Origin: "viewport"}
+			if step.target != nil {
+				el, err := webDriverTarget(step.target)
+				if err != nil {
+					return nil, err
+				}
+				wheelAction.Origin = el
+			}
+		case webdriver.ActionPause:
+			pointerAction.Duration = int(step.duration / time.Millisecond)
+		}
+
+		keys.Actions = append(keys.Actions, keyAction)
+		pointer.Actions = append(pointer.Actions, pointerAction)
+		wheel.Actions = append(wheel.Actions, wheelAction)
+	}
+	return []webdriver.ActionSequence{keys, pointer, wheel}, nil
+}
+
+// webDriverTarget returns the web element reference of an element used as an action origin
+func webDriverTarget(el Element) (*webdriver.Element, error) {
+	e, ok := unwrapElement(el).(*webDriverElement)
+	if !ok {
+		return nil, fmt.Errorf("element does not belong to this session: %w", ErrInvalidArgument)
+	}
+	return e.el, nil
+}
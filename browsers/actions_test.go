@@ -0,0 +1,100 @@
+package browsers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+func TestWebDriverActions(t *testing.T) {
+	el := &webDriverElement{el: &webdriver.Element{ID: "el-1"}}
+	pause := webdriver.Action{Type: webdriver.ActionPause}
+	tests := []struct {
+		name    string
+		actions *Actions
+		keys    []webdriver.Action
+		pointer []webdriver.Action
+		wheel   []webdriver.Action
+	}{
+		{
+			name:    "key press",
+			actions: NewActions().Press("a"),
+			keys: []webdriver.Action{
+				{Type: webdriver.ActionKeyDown, Value: "a"},
+				{Type: webdriver.ActionKeyUp, Value: "a"},
+			},
+			pointer: []webdriver.Action{pause, pause},
+			wheel:   []webdriver.Action{pause, pause},
+		},
+		{
+			name:    "shift click on element",
+			actions: NewActions().KeyDown(KeyShift).MoveTo(el).Click(MouseLeft).KeyUp(KeyShift),
+			keys: []webdriver.Action{
+				{Type: webdriver.ActionKeyDown, Value: KeyShift},
+				pause, pause, pause,
+				{Type: webdriver.ActionKeyUp, Value: KeyShift},
+			},
+			pointer: []webdriver.Action{
+				pause,
+				{Type: webdriver.ActionPointerMove, Origin: el.el},
+				{Type: webdriver.ActionPointerDown, Button: int(MouseLeft)},
+				{Type: webdriver.ActionPointerUp, Button: int(MouseLeft)},
+				pause,
+			},
+			wheel: []webdriver.Action{pause, pause, pause, pause, pause},
+		},
+		{
+			name:    "moves, wheel and pause",
+			actions: NewActions().MoveToPoint(10, 20).MoveBy(5, -5).Wheel(nil, 0, 300).Pause(250 * time.Millisecond),
+			keys:    []webdriver.Action{pause, pause, pause, pause},
+			pointer: []webdriver.Action{
+				{Type: webdriver.ActionPointerMove, X: 10, Y: 20, Origin: "viewport"},
+				{Type: webdriver.ActionPointerMove, X: 5, Y: -5, Origin: "pointer"},
+				pause,
+				{Type: webdriver.ActionPause, Duration: 250},
+			},
+			wheel: []webdriver.Action{
+				pause, pause,
+				{Type: webdriver.ActionScroll, DeltaY: 300, Origin: "viewport"},
+				pause,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequences, err := webDriverActions(tt.actions)
+			if err != nil {
+				t.Fatalf("webDriverActions() error = %v", err)
+			}
+			if len(sequences) != 3 {
+				t.Fatalf("webDriverActions() returned %d sequences, want 3", len(sequences))
+			}
+			for i, want := range [][]webdriver.Action{tt.keys, tt.pointer, tt.wheel} {
+				if got := sequences[i].Actions; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s actions = %+v, want %+v", sequences[i].Type, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWebDriverActionsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions *Actions
+	}{
+		{"key of several characters", NewActions().KeyDown("ab")},
+		{"element of another browser", NewActions().MoveTo(&chromeElement{})},
+		{"wheel over element of another browser", NewActions().Wheel(&chromeElement{}, 0, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := webDriverActions(tt.actions); !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("webDriverActions() error = %v, want ErrInvalidArgument", err)
+			}
+		})
+	}
+}
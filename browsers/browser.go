@@ -3,6 +3,7 @@
 package browsers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -10,38 +11,114 @@ import (
 	"github.com/valdemart123/go-owl/config"
 )
 
-// Browser interface to unify browser handling
+// Browser interface to unify browser handling.
+// NewContext returns an isolated browser sharing the launched one, with its own cookies and storage.
+// The Context variants give up when the context is done; elements found
+// through them perform their actions under the same context.
 type Browser interface {
 	Launch() error
+	LaunchContext(ctx context.Context) error
 	Close() error
+	CloseContext(ctx context.Context) error
+	NewContext() (Browser, error)
 	OpenURL(url string) error
+	OpenURLContext(ctx context.Context, url string) error
+	NewPage() (PageID, error)
+	Pages() ([]PageID, error)
+	CurrentPage() (PageID, error)
+	SwitchTo(page PageID) error
+	ClosePage() error
+	WaitForPage(action func() error) (PageID, error)
+	WindowRect() (WindowRect, error)
+	SetWindowRect(rect WindowRect) error
+	MaximizeWindow() error
+	MinimizeWindow() error
+	Emulate(device Device) error
+	SetGeolocation(location *Geolocation) error
+	SetTimezone(timezone string) error
+	SetLocale(locale string) error
+	EmulateMedia(features MediaFeatures) error
+	GrantPermissions(origin string, permissions ...Permission) error
+	DenyPermissions(origin string, permissions ...Permission) error
+	ResetPermissions() error
+	Frame(selector string) error
+	Frames() ([]FrameInfo, error)
+	ParentFrame() error
+	MainFrame() error
+	Find(selector string) (Element, error)
+	FindContext(ctx context.Context, selector string) (Element, error)
+	FindAll(selector string) ([]Element, error)
+	FindAllContext(ctx context.Context, selector string) ([]Element, error)
+	SetWaitOptions(opts WaitOptions)
+	WaitLoad(state LoadState) error
+	WaitFor(selector string, condition Condition) error
+	OnDialog(handler DialogHandler)
+	Dialogs() []Dialog
+	ConsoleMessages() []ConsoleMessage
+	PageErrors() []PageError
+	OnConsole(onMessage func(ConsoleMessage), onError func(PageError))
+	CheckPageErrors() error
+	WaitForDownload(action func() error) (Download, error)
+	Route(match RouteMatch, handler RouteHandler) error
+	Unroute(match RouteMatch) error
+	StartHAR(path string) error
+	StopHAR() error
+	RouteFromHAR(path string) error
+	ThrottleNetwork(conditions NetworkConditions) error
+	ThrottleCPU(rate float64) error
+	Perform(actions *Actions) error
+	ReleaseActions() error
+	Screenshot() ([]byte, error)
+	FullPageScreenshot() ([]byte, error)
+	PDF(opts PDFOptions) ([]byte, error)
+	StartVideo(dir string) error
+	StopVideo(keep bool) ([]string, error)
+	SetFailed(failed bool)
+	Evaluate(script string, args ...interface{}) (interface{}, error)
+	EvaluateAsync(script string, args ...interface{}) (interface{}, error)
+	Cookies() ([]Cookie, error)
+	SetCookies(cookies ...Cookie) error
+	DeleteCookies(names ...string) error
+	StorageItem(area StorageArea, key string) (string, bool, error)
+	StorageItems(area StorageArea) (map[string]string, error)
+	SetStorageItem(area StorageArea, key, value string) error
+	ClearStorage(area StorageArea) error
+	StorageState() (*StorageState, error)
+	SetStorageState(state *StorageState) error
+	SaveStorageState(path string) error
+	LoadStorageState(path string) error
 }
 
 // GetBrowser initializes the correct browser based on JSON config
 func GetBrowser() (Browser, error) {
-	browserType := config.LoadBrowserType()
+	return GetBrowserContext(context.Background())
+}
+
+// GetBrowserContext initializes the correct browser based on JSON config, giving up when ctx is done
+func GetBrowserContext(ctx context.Context) (Browser, error) {
+	conf := config.LoadConfig()
+	browserType := conf.Browser.Type
 	log.Printf("Selected browser: %s\n", browserType)
 
+	var browser Browser
 	switch browserType {
 	case "chrome":
-		browser := &Chrome{}
-		if err := browser.Launch(); err != nil {
-			return nil, err
-		}
-		return browser, nil
+		browser = &Chrome{Options: conf.Launch}
 	case "firefox":
-		browser := &Firefox{}
-		if err := browser.Launch(); err != nil {
-			return nil, err
-		}
-		return browser, nil
+		browser = &Firefox{Options: conf.Launch}
 	case "webkit":
-		browser := &WebKit{}
-		if err := browser.Launch(); err != nil {
-			return nil, err
-		}
-		return browser, nil
+		browser = &WebKit{Options: conf.Launch}
 	default:
 		return nil, errors.New(fmt.Sprintf("Unsupported browser type: %s", browserType))
 	}
-}
\ No newline at end of file
+
+	if err := browser.LaunchContext(ctx); err != nil {
+		return nil, err
+	}
+	if conf.Launch.TraceDir != "" {
+		tracer := NewTracer(browser)
+		tracer.traceDir = conf.Launch.TraceDir
+		return tracer, nil
+	}
+	return browser, nil
+}
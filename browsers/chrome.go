@@ -1,38 +1,473 @@
 package browsers
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/config"
 )
 
 // Chrome struct using Rod
 type Chrome struct {
 	Browser *rod.Browser
 	Page    *rod.Page
+
+	// Options are the launch options from the config
+	Options config.LaunchConfig
+
+	launcher *launcher.Launcher
+	wait     WaitOptions
+	frames   []*rod.Page
+	input    chromeInput
+	dialogs  dialogState
+
+	stopDialogs func()
+	downloadDir string // temporary download directory, removed on close
+	visited     originSet
+	stopOrigins func()
+
+	routes          routeState
+	routeMu         sync.Mutex
+	routers         map[proto.TargetTargetID]*rod.HijackRouter // nil while no requests are intercepted
+	stopNewPages    func()
+	har             harRecorder
+	stopHAR         func()
+	console         consoleState
+	stopConsole     func()
+	stopEmulation   func()
+	environment     pageEnvironment
+	stopEnvironment func()
+	video           videoRecorder
+	stopVideo       func()
+	failed          bool
 }
 
 // Launch starts a new Chrome browser instance
 func (c *Chrome) Launch() error {
+	return c.LaunchContext(context.Background())
+}
+
+// LaunchContext starts a new Chrome browser instance, giving up when ctx is done
+func (c *Chrome) LaunchContext(ctx context.Context) error {
 	log.Println("Launching Chrome...")
-	c.Browser = rod.New().MustConnect()
+	c.launcher = c.newLauncher().Context(ctx)
+	controlURL, err := c.launcher.Launch()
+	if err != nil {
+		return fmt.Errorf("failed to launch Chrome: %w", err)
+	}
+
+	browser := rod.New().ControlURL(controlURL).Context(ctx).
+		SlowMotion(time.Duration(c.Options.SlowMo) * time.Millisecond)
+	if c.Options.WindowSize.Width > 0 && c.Options.WindowSize.Height > 0 {
+		// Let the window size decide the viewport instead of Rod's default device
+		browser = browser.NoDefaultDevice()
+	}
+	if err := browser.Connect(); err != nil {
+		c.launcher.Kill()
+		return fmt.Errorf("failed to connect to Chrome: %w", err)
+	}
+
+	if err := c.setDownloadDir(browser); err != nil {
+		browser.Close()
+		c.launcher.Kill()
+		return err
+	}
+
+	// Keep the connection independent from the launch deadline
+	c.Browser = browser.Context(context.Background())
+
+	if err := c.handleDialogs(); err != nil {
+		c.Close()
+		return err
+	}
+	if err := c.trackOrigins(); err != nil {
+		c.Close()
+		return err
+	}
+	if err := c.captureConsole(); err != nil {
+		c.Close()
+		return err
+	}
+	if err := c.applyOptions(); err != nil {
+		c.Close()
+		return err
+	}
+	// Contexts made by NewContext are not recorded, they would overwrite the same file
+	if c.Options.RecordHAR != "" {
+		if err := c.StartHAR(c.Options.RecordHAR); err != nil {
+			c.Close()
+			return err
+		}
+	}
 	return nil
 }
 
+// NewContext opens an incognito browser context in the launched Chrome.
+// The context has its own cookies and storage and is disposed by its Close.
+func (c *Chrome) NewContext() (Browser, error) {
+	if c.Browser == nil {
+		return nil, fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	browser, err := c.Browser.Incognito()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser context: %w", chromeError(err))
+	}
+
+	isolated := &Chrome{Browser: browser, Options: c.Options, wait: c.wait}
+	if err := isolated.setDownloadDir(browser); err != nil {
+		isolated.Close()
+		return nil, err
+	}
+	if err := isolated.handleDialogs(); err != nil {
+		isolated.Close()
+		return nil, err
+	}
+	if err := isolated.trackOrigins(); err != nil {
+		isolated.Close()
+		return nil, err
+	}
+	if err := isolated.captureConsole(); err != nil {
+		isolated.Close()
+		return nil, err
+	}
+	if err := isolated.applyOptions(); err != nil {
+		isolated.Close()
+		return nil, err
+	}
+	return isolated, nil
+}
+
+// applyOptions applies the launch options that take effect once the browser context exists
+func (c *Chrome) applyOptions() error {
+	device, err := deviceFromConfig(c.Options.Emulation)
+	if err != nil {
+		return err
+	}
+	if device != (Device{}) {
+		if err := c.Emulate(device); err != nil {
+			return err
+		}
+	}
+	if env := environmentFromConfig(c.Options); env != (pageEnvironment{}) {
+		c.environment = env
+		if err := c.emulateEnvironment("launch environment"); err != nil {
+			return err
+		}
+	}
+	if len(c.Options.Permissions) > 0 {
+		permissions := make([]Permission, len(c.Options.Permissions))
+		for i, permission := range c.Options.Permissions {
+			permissions[i] = Permission(permission)
+		}
+		if err := c.GrantPermissions("", permissions...); err != nil {
+			return err
+		}
+	}
+	if c.Options.VideoDir != "" {
+		if err := c.StartVideo(c.Options.VideoDir); err != nil {
+			return err
+		}
+	}
+	if c.Options.StorageState != "" {
+		if err := c.LoadStorageState(c.Options.StorageState); err != nil {
+			return fmt.Errorf("failed to restore storage state: %w", err)
+		}
+	}
+	if c.Options.ReplayHAR != "" {
+		if err := c.RouteFromHAR(c.Options.ReplayHAR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setDownloadDir lets the browser context save downloads into the configured directory
+func (c *Chrome) setDownloadDir(browser *rod.Browser) error {
+	if c.Options.DownloadDir == "" {
+		return nil
+	}
+	err := proto.BrowserSetDownloadBehavior{
+		Behavior:         proto.BrowserSetDownloadBehaviorBehaviorAllow,
+		BrowserContextID: browser.BrowserContextID,
+		DownloadPath:     c.Options.DownloadDir,
+		EventsEnabled:    true,
+	}.Call(browser)
+	if err != nil {
+		return fmt.Errorf("failed to set download directory: %w", chromeError(err))
+	}
+	return nil
+}
+
+// newLauncher builds the Chrome command line from the launch options
+func (c *Chrome) newLauncher() *launcher.Launcher {
+	opts := c.Options
+	l := launcher.New().Headless(opts.IsHeadless(true))
+
+	if opts.Binary != "" {
+		l = l.Bin(opts.Binary)
+	}
+	if opts.WindowSize.Width > 0 && opts.WindowSize.Height > 0 {
+		l = l.Set("window-size", strconv.Itoa(opts.WindowSize.Width), strconv.Itoa(opts.WindowSize.Height))
+	}
+	if opts.Devtools {
+		l = l.Devtools(true)
+	}
+	if opts.UserDataDir != "" {
+		l = l.UserDataDir(opts.UserDataDir)
+	}
+	if opts.Locale != "" {
+		l = l.Set("lang", opts.Locale).Set("accept-lang", opts.Locale)
+	}
+	if opts.Timezone != "" {
+		l = l.Env(append(os.Environ(), "TZ="+opts.Timezone)...)
+	}
+	for _, arg := range opts.Args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if hasValue {
+			l = l.Set(flags.Flag(name), value)
+		} else {
+			l = l.Set(flags.Flag(name))
+		}
+	}
+	return l
+}
+
 // Close shuts down the Chrome browser instance
 func (c *Chrome) Close() error {
-	if c.Browser != nil {
-		c.Browser.MustClose()
-		log.Println("Chrome browser closed successfully.")
+	return c.CloseContext(context.Background())
+}
+
+// CloseContext shuts down the Chrome browser instance, killing the process if ctx is done first
+func (c *Chrome) CloseContext(ctx context.Context) error {
+	if c.Browser == nil {
+		return nil
 	}
+
+	c.ReleaseActions()
+	harErr := c.StopHAR()
+	videoErr := c.closeVideo()
+	c.stopRouting()
+	if c.stopDialogs != nil {
+		c.stopDialogs()
+		c.stopDialogs = nil
+	}
+	if c.stopOrigins != nil {
+		c.stopOrigins()
+		c.stopOrigins = nil
+	}
+	if c.stopConsole != nil {
+		c.stopConsole()
+		c.stopConsole = nil
+	}
+	if c.stopEmulation != nil {
+		c.stopEmulation()
+		c.stopEmulation = nil
+	}
+	if c.stopEnvironment != nil {
+		c.stopEnvironment()
+		c.stopEnvironment = nil
+	}
+	err := c.Browser.Context(ctx).Close()
+	if c.launcher != nil {
+		c.launcher.Kill()
+		// Only remove the temporary profile, a configured one is meant to be reused
+		if c.Options.UserDataDir == "" {
+			c.launcher.Cleanup()
+		}
+	}
+	if c.downloadDir != "" {
+		os.RemoveAll(c.downloadDir)
+		c.downloadDir = ""
+	}
+	c.Browser, c.Page, c.frames = nil, nil, nil
+	var closeErr, pageErr error
+	if err != nil && ctx.Err() == nil {
+		closeErr = fmt.Errorf("failed to close Chrome: %w", chromeError(err))
+	}
+	if c.Options.FailOnPageError {
+		pageErr = c.CheckPageErrors()
+	}
+	if err := errors.Join(closeErr, harErr, videoErr, pageErr); err != nil {
+		return err
+	}
+
+	log.Println("Chrome browser closed successfully.")
 	return nil
 }
 
+// OpenURL navigates the current page to the URL, opening a page if there is none
 func (c *Chrome) OpenURL(url string) error {
+	return c.OpenURLContext(context.Background(), url)
+}
+
+// OpenURLContext navigates the current page to the URL and waits for it to load, giving up when ctx is done
+func (c *Chrome) OpenURLContext(ctx context.Context, url string) error {
 	if c.Browser == nil {
-		return errors.New("browser not launched")
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	if c.Page == nil {
+		page, err := c.Browser.Context(ctx).Page(proto.TargetCreateTarget{})
+		if err != nil {
+			return fmt.Errorf("failed to open page: %w", chromeError(err))
+		}
+		c.usePage(page.Context(context.Background()))
+	}
+
+	c.frames = nil
+	if err := c.Page.Context(ctx).Navigate(url); err != nil {
+		return fmt.Errorf("failed to open URL: %w", chromeError(err))
+	}
+	return c.waitLoad(ctx, LoadStateLoad)
+}
+
+// SetWaitOptions sets the timeout and polling interval used by waits and auto-waiting actions
+func (c *Chrome) SetWaitOptions(opts WaitOptions) {
+	c.wait = opts
+}
+
+// WaitLoad waits until the current page reaches the load state
+func (c *Chrome) WaitLoad(state LoadState) error {
+	return c.waitLoad(context.Background(), state)
+}
+
+func (c *Chrome) waitLoad(ctx context.Context, state LoadState) error {
+	if c.Page == nil {
+		return fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	page := c.Page.Context(ctx).Timeout(c.wait.withDefaults().Timeout)
+	defer page.CancelTimeout()
+
+	var err error
+	switch state {
+	case LoadStateDOMContentLoaded:
+		err = poll(ctx, c.wait, func() (bool, error) {
+			res, err := page.Eval(`() => document.readyState`)
+			if err != nil {
+				return false, err
+			}
+			return res.Value.Str() != "loading", nil
+		})
+	case LoadStateLoad:
+		err = page.WaitLoad()
+	case LoadStateNetworkIdle:
+		if err = page.WaitLoad(); err == nil {
+			page.WaitRequestIdle(networkIdleTime, nil, nil, nil)()
+		}
+	default:
+		return fmt.Errorf("unknown load state %q: %w", state, ErrInvalidArgument)
+	}
+	if err != nil {
+		return fmt.Errorf("waiting for %s: %w", state, chromeError(err))
 	}
-	c.Page = c.Browser.MustPage(url)
 	return nil
 }
+
+// WaitFor waits until the elements matching the CSS selector satisfy the condition
+func (c *Chrome) WaitFor(selector string, condition Condition) error {
+	ctx := context.Background()
+	return waitFor(ctx, c.wait, selector, condition, func(selector string) ([]Element, error) {
+		return c.FindAllContext(ctx, selector)
+	})
+}
+
+// Find waits for the first element matching the CSS selector on the current page
+func (c *Chrome) Find(selector string) (Element, error) {
+	return c.FindContext(context.Background(), selector)
+}
+
+// FindContext waits for the first element matching the CSS selector, giving up when ctx is done.
+// The returned element performs its actions under ctx.
+func (c *Chrome) FindContext(ctx context.Context, selector string) (Element, error) {
+	if c.Page == nil {
+		return nil, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	page := c.scope().Context(ctx).Sleeper(rod.NotFoundSleeper)
+	el, err := waitFind(ctx, c.wait, selector, func(selector string) (Element, error) {
+		el, err := chromeFind(page, selector)
+		if err != nil {
+			return nil, chromeError(err)
+		}
+		return &chromeElement{el: el, wait: c.wait, ctx: ctx}, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find element %q: %w", selector, err)
+	}
+	return el, nil
+}
+
+// FindAll locates all elements matching the CSS selector on the current page
+func (c *Chrome) FindAll(selector string) ([]Element, error) {
+	return c.FindAllContext(context.Background(), selector)
+}
+
+// FindAllContext locates all elements matching the CSS selector, giving up when ctx is done.
+// The returned elements perform their actions under ctx.
+func (c *Chrome) FindAllContext(ctx context.Context, selector string) ([]Element, error) {
+	if c.Page == nil {
+		return nil, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	els, err := chromeFindAll(c.scope().Context(ctx), selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find elements %q: %w", selector, chromeError(err))
+	}
+	elements := make([]Element, len(els))
+	for i, el := range els {
+		elements[i] = &chromeElement{el: el, wait: c.wait, ctx: ctx}
+	}
+	return elements, nil
+}
+
+// chromeElement wraps a Rod element bound to the context it was found with
+type chromeElement struct {
+	el   *rod.Element
+	wait WaitOptions
+	ctx  context.Context
+}
+
+func (e *chromeElement) Click() error {
+	if err := waitActionable(e.ctx, e.wait, e); err != nil {
+		return err
+	}
+	return chromeError(e.el.Click(proto.InputMouseButtonLeft, 1))
+}
+
+func (e *chromeElement) Type(text string) error {
+	if err := waitActionable(e.ctx, e.wait, e); err != nil {
+		return err
+	}
+	return chromeError(e.el.Input(text))
+}
+
+func (e *chromeElement) Text() (string, error) {
+	text, err := e.el.Text()
+	return text, chromeError(err)
+}
+
+func (e *chromeElement) Attribute(name string) (string, error) {
+	value, err := e.el.Attribute(name)
+	if err != nil || value == nil {
+		return "", chromeError(err)
+	}
+	return *value, nil
+}
+
+func (e *chromeElement) Visible() (bool, error) {
+	visible, err := e.el.Visible()
+	return visible, chromeError(err)
+}
+
+func (e *chromeElement) Enabled() (bool, error) {
+	disabled, err := e.el.Disabled()
+	return !disabled, chromeError(err)
+}
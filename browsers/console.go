@@ -0,0 +1,296 @@
+package browsers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// ConsoleLevel is the severity of a console message
+type ConsoleLevel string
+
+// Console message levels
+const (
+	ConsoleDebug   ConsoleLevel = "debug"
+	ConsoleInfo    ConsoleLevel = "info"
+	ConsoleWarning ConsoleLevel = "warning"
+	ConsoleError   ConsoleLevel = "error"
+)
+
+// SourceLocation is a position in a script, its line and column are zero-based
+type SourceLocation struct {
+	URL    string
+	Line   int
+	Column int
+}
+
+// ConsoleMessage is a message a page logged to the console
+type ConsoleMessage struct {
+	Level ConsoleLevel
+	// Type is the console method called, such as "log", "warn" or "table"
+	Type     string
+	Text     string
+	Location SourceLocation
+	// Args are the logged values. Objects are passed by reference and only described by their type.
+	Args []interface{}
+}
+
+// PageError is an exception a page threw without catching it
+type PageError struct {
+	Message  string
+	Stack    string
+	Location SourceLocation
+}
+
+func (e PageError) Error() string {
+	if e.Location.URL == "" {
+		return "uncaught page error: " + e.Message
+	}
+	return fmt.Sprintf("uncaught page error at %s:%d:%d: %s", e.Location.URL, e.Location.Line+1, e.Location.Column+1, e.Message)
+}
+
+// consoleState holds the console messages and page errors collected so far with their handlers.
+// Messages arrive on event goroutines, so access is guarded.
+type consoleState struct {
+	mu        sync.Mutex
+	messages  []ConsoleMessage
+	errors    []PageError
+	onMessage func(ConsoleMessage)
+	onError   func(PageError)
+}
+
+func (s *consoleState) setHandlers(onMessage func(ConsoleMessage), onError func(PageError)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onMessage, s.onError = onMessage, onError
+}
+
+func (s *consoleState) addMessage(msg ConsoleMessage) {
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	handler := s.onMessage
+	s.mu.Unlock()
+	if handler != nil {
+		handler(msg)
+	}
+}
+
+func (s *consoleState) addError(pageErr PageError) {
+	s.mu.Lock()
+	s.errors = append(s.errors, pageErr)
+	handler := s.onError
+	s.mu.Unlock()
+	if handler != nil {
+		handler(pageErr)
+	}
+}
+
+func (s *consoleState) listMessages() []ConsoleMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ConsoleMessage(nil), s.messages...)
+}
+
+func (s *consoleState) listErrors() []PageError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]PageError(nil), s.errors...)
+}
+
+// check joins the page errors into one error, nil when there are none
+func (s *consoleState) check() error {
+	var errs []error
+	for _, pageErr := range s.listErrors() {
+		errs = append(errs, pageErr)
+	}
+	return errors.Join(errs...)
+}
+
+// ConsoleMessages returns the console messages of all pages so far, oldest first
+func (c *Chrome) ConsoleMessages() []ConsoleMessage {
+	return c.console.listMessages()
+}
+
+// PageErrors returns the uncaught errors of all pages so far, oldest first
+func (c *Chrome) PageErrors() []PageError {
+	return c.console.listErrors()
+}
+
+// OnConsole sets the handlers called for each console message and uncaught page error, nil removes them.
+// Handlers run on event goroutines.
+func (c *Chrome) OnConsole(onMessage func(ConsoleMessage), onError func(PageError)) {
+	c.console.setHandlers(onMessage, onError)
+}
+
+// CheckPageErrors returns the uncaught page errors so far as one error, nil when there were none
+func (c *Chrome) CheckPageErrors() error {
+	return c.console.check()
+}
+
+// captureConsole collects the console messages and uncaught errors of every page in the browser context until Close
+func (c *Chrome) captureConsole() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopConsole = cancel
+	var mu sync.Mutex
+	captured := map[proto.TargetTargetID]bool{}
+	err := c.watchPages(ctx, func(page *rod.Page) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !captured[page.TargetID] {
+			captured[page.TargetID] = true
+			c.capturePage(ctx, page)
+		}
+		return nil
+	})
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to capture console: %w", err)
+	}
+	return nil
+}
+
+// capturePage collects the console messages and uncaught errors of the page until ctx is done
+func (c *Chrome) capturePage(ctx context.Context, page *rod.Page) {
+	wait := page.Context(ctx).EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+		msg := ConsoleMessage{Level: chromeConsoleLevel(e.Type), Type: string(e.Type)}
+		texts := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			var value interface{}
+			value, texts[i] = chromeConsoleArg(arg)
+			msg.Args = append(msg.Args, value)
+		}
+		msg.Text = strings.Join(texts, " ")
+		if e.StackTrace != nil && len(e.StackTrace.CallFrames) > 0 {
+			frame := e.StackTrace.CallFrames[0]
+			msg.Location = SourceLocation{URL: frame.URL, Line: frame.LineNumber, Column: frame.ColumnNumber}
+		}
+		c.console.addMessage(msg)
+	}, func(e *proto.RuntimeExceptionThrown) {
+		details := e.ExceptionDetails
+		pageErr := PageError{
+			Message:  details.Text,
+			Location: SourceLocation{URL: details.URL, Line: details.LineNumber, Column: details.ColumnNumber},
+		}
+		// The description of a thrown Error is its stack, starting with the message
+		if details.Exception != nil && details.Exception.Description != "" {
+			pageErr.Stack = details.Exception.Description
+			pageErr.Message, _, _ = strings.Cut(pageErr.Stack, "\n")
+		}
+		c.console.addError(pageErr)
+	})
+	go wait()
+}
+
+func chromeConsoleLevel(method proto.RuntimeConsoleAPICalledType) ConsoleLevel {
+	switch method {
+	case proto.RuntimeConsoleAPICalledTypeDebug:
+		return ConsoleDebug
+	case proto.RuntimeConsoleAPICalledTypeWarning:
+		return ConsoleWarning
+	case proto.RuntimeConsoleAPICalledTypeError, proto.RuntimeConsoleAPICalledTypeAssert:
+		return ConsoleError
+	}
+	return ConsoleInfo
+}
+
+// chromeConsoleArg returns the value of a console argument and how the console prints it
+func chromeConsoleArg(arg *proto.RuntimeRemoteObject) (interface{}, string) {
+	switch {
+	case arg.Type == proto.RuntimeRemoteObjectTypeUndefined:
+		return nil, "undefined"
+	case arg.Subtype == proto.RuntimeRemoteObjectSubtypeNull:
+		return nil, "null"
+	case arg.UnserializableValue != "":
+		return string(arg.UnserializableValue), string(arg.UnserializableValue)
+	case arg.Type == proto.RuntimeRemoteObjectTypeObject, arg.Type == proto.RuntimeRemoteObjectTypeFunction:
+		return arg.Description, arg.Description
+	}
+	return arg.Value.Val(), arg.Value.String()
+}
+
+// ConsoleMessages returns the console messages of the session so far, oldest first.
+// They are collected through WebDriver BiDi, Safari offers none and collects nothing.
+func (b *webDriverBrowser) ConsoleMessages() []ConsoleMessage {
+	return b.console.listMessages()
+}
+
+// PageErrors returns the uncaught errors of the session so far, oldest first
+func (b *webDriverBrowser) PageErrors() []PageError {
+	return b.console.listErrors()
+}
+
+// OnConsole sets the handlers called for each console message and uncaught page error, nil removes them.
+// Handlers run on the BiDi event goroutine.
+func (b *webDriverBrowser) OnConsole(onMessage func(ConsoleMessage), onError func(PageError)) {
+	b.console.setHandlers(onMessage, onError)
+}
+
+// CheckPageErrors returns the uncaught page errors so far as one error, nil when there were none
+func (b *webDriverBrowser) CheckPageErrors() error {
+	return b.console.check()
+}
+
+// captureConsole collects the console messages and uncaught errors of the session
+func (b *webDriverBrowser) captureConsole(ctx context.Context) error {
+	b.bidi.OnLogEntry(func(e webdriver.LogEntry) {
+		var location SourceLocation
+		if e.StackTrace != nil && len(e.StackTrace.CallFrames) > 0 {
+			frame := e.StackTrace.CallFrames[0]
+			location = SourceLocation{URL: frame.URL, Line: frame.LineNumber, Column: frame.ColumnNumber}
+		}
+
+		if e.Type == webdriver.LogEntryJavascript {
+			b.console.addError(PageError{Message: e.Text, Stack: bidiStack(e.StackTrace), Location: location})
+			return
+		}
+		msg := ConsoleMessage{Level: bidiConsoleLevel(e.Level), Type: e.Method, Text: e.Text, Location: location}
+		for _, arg := range e.Args {
+			msg.Args = append(msg.Args, bidiConsoleArg(arg))
+		}
+		b.console.addMessage(msg)
+	})
+	if err := b.bidi.Subscribe(ctx, "log.entryAdded"); err != nil {
+		return fmt.Errorf("failed to capture console: %w", err)
+	}
+	return nil
+}
+
+func bidiConsoleLevel(level string) ConsoleLevel {
+	if level == "warn" {
+		return ConsoleWarning
+	}
+	return ConsoleLevel(level)
+}
+
+// bidiConsoleArg returns the value of a console argument, describing values passed by reference by their type
+func bidiConsoleArg(arg webdriver.RemoteValue) interface{} {
+	switch arg.Type {
+	case "undefined", "null":
+		return nil
+	case "string", "number", "boolean", "bigint":
+		var value interface{}
+		if err := json.Unmarshal(arg.Value, &value); err == nil {
+			return value
+		}
+	}
+	return arg.Type
+}
+
+// bidiStack formats the stack trace the way Firefox prints it, innermost frame first
+func bidiStack(trace *webdriver.StackTrace) string {
+	if trace == nil {
+		return ""
+	}
+	lines := make([]string, len(trace.CallFrames))
+	for i, frame := range trace.CallFrames {
+		lines[i] = fmt.Sprintf("%s@%s:%d:%d", frame.FunctionName, frame.URL, frame.LineNumber+1, frame.ColumnNumber+1)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,285 @@
+package browsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Cookie is a browser cookie
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Expires  int64  `json:"expires,omitempty"` // Unix seconds, zero for session cookies
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	SameSite string `json:"sameSite,omitempty"` // Strict, Lax or None
+}
+
+// StorageArea selects the web storage a storage call operates on
+type StorageArea string
+
+// Web storage areas
+const (
+	LocalStorage   StorageArea = "localStorage"
+	SessionStorage StorageArea = "sessionStorage"
+)
+
+// evaluator runs scripts in the current page, used to reach web storage
+type evaluator interface {
+	Evaluate(script string, args ...interface{}) (interface{}, error)
+}
+
+// storageItem reads a key from the storage area, reporting whether it exists
+func storageItem(e evaluator, area StorageArea, key string) (string, bool, error) {
+	value, err := e.Evaluate(`(area, key) => window[area].getItem(key)`, string(area), key)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", area, err)
+	}
+	str, ok := value.(string)
+	return str, ok, nil
+}
+
+// storageItems reads all keys of the storage area
+func storageItems(e evaluator, area StorageArea) (map[string]string, error) {
+	value, err := e.Evaluate(`(area) => Object.fromEntries(Object.entries(window[area]))`, string(area))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", area, err)
+	}
+	entries, _ := value.(map[string]interface{})
+	items := make(map[string]string, len(entries))
+	for key, item := range entries {
+		items[key], _ = item.(string)
+	}
+	return items, nil
+}
+
+// setStorageItem writes a key into the storage area
+func setStorageItem(e evaluator, area StorageArea, key, value string) error {
+	if _, err := e.Evaluate(`(area, key, value) => window[area].setItem(key, value)`, string(area), key, value); err != nil {
+		return fmt.Errorf("failed to write %s: %w", area, err)
+	}
+	return nil
+}
+
+// clearStorage removes all keys from the storage area
+func clearStorage(e evaluator, area StorageArea) error {
+	if _, err := e.Evaluate(`(area) => window[area].clear()`, string(area)); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", area, err)
+	}
+	return nil
+}
+
+// Cookies returns the cookies visible to the current page
+func (c *Chrome) Cookies() ([]Cookie, error) {
+	if c.Page == nil {
+		return nil, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	res, err := proto.NetworkGetCookies{}.Call(c.Page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", chromeError(err))
+	}
+
+	return chromeCookies(res.Cookies), nil
+}
+
+// SetCookies adds cookies, scoping those without a domain to the current page
+func (c *Chrome) SetCookies(cookies ...Cookie) error {
+	if c.Page == nil {
+		return fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	info, err := c.Page.Info()
+	if err != nil {
+		return fmt.Errorf("failed to set cookies: %w", chromeError(err))
+	}
+
+	params := chromeCookieParams(cookies)
+	for i, cookie := range cookies {
+		if cookie.Domain == "" {
+			params[i].URL = info.URL
+		}
+	}
+	if err := (proto.NetworkSetCookies{Cookies: params}).Call(c.Page); err != nil {
+		return fmt.Errorf("failed to set cookies: %w", chromeError(err))
+	}
+	return nil
+}
+
+// DeleteCookies removes the named cookies visible to the current page, or all of them when no name is given
+func (c *Chrome) DeleteCookies(names ...string) error {
+	cookies, err := c.Cookies()
+	if err != nil {
+		return err
+	}
+	for _, cookie := range cookies {
+		if len(names) > 0 && !contains(names, cookie.Name) {
+			continue
+		}
+		err := proto.NetworkDeleteCookies{Name: cookie.Name, Domain: cookie.Domain, Path: cookie.Path}.Call(c.Page)
+		if err != nil {
+			return fmt.Errorf("failed to delete cookie %q: %w", cookie.Name, chromeError(err))
+		}
+	}
+	return nil
+}
+
+// chromeCookies converts cookies reported by CDP
+func chromeCookies(res []*proto.NetworkCookie) []Cookie {
+	cookies := make([]Cookie, len(res))
+	for i, cookie := range res {
+		cookies[i] = Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			HTTPOnly: cookie.HTTPOnly,
+			Secure:   cookie.Secure,
+			SameSite: string(cookie.SameSite),
+		}
+		if !cookie.Session {
+			cookies[i].Expires = int64(cookie.Expires)
+		}
+	}
+	return cookies
+}
+
+// chromeCookieParams converts cookies into CDP parameters for setting them
+func chromeCookieParams(cookies []Cookie) []*proto.NetworkCookieParam {
+	params := make([]*proto.NetworkCookieParam, len(cookies))
+	for i, cookie := range cookies {
+		params[i] = &proto.NetworkCookieParam{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HTTPOnly,
+			SameSite: proto.NetworkCookieSameSite(cookie.SameSite),
+			Expires:  proto.TimeSinceEpoch(cookie.Expires),
+		}
+	}
+	return params
+}
+
+// StorageItem reads a key from local or session storage of the current page
+func (c *Chrome) StorageItem(area StorageArea, key string) (string, bool, error) {
+	return storageItem(c, area, key)
+}
+
+// StorageItems reads all keys from local or session storage of the current page
+func (c *Chrome) StorageItems(area StorageArea) (map[string]string, error) {
+	return storageItems(c, area)
+}
+
+// SetStorageItem writes a key into local or session storage of the current page
+func (c *Chrome) SetStorageItem(area StorageArea, key, value string) error {
+	return setStorageItem(c, area, key, value)
+}
+
+// ClearStorage removes all keys from local or session storage of the current page
+func (c *Chrome) ClearStorage(area StorageArea) error {
+	return clearStorage(c, area)
+}
+
+// Cookies returns the cookies visible to the current page
+func (b *webDriverBrowser) Cookies() ([]Cookie, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	res, err := b.session.Cookies(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", err)
+	}
+
+	cookies := make([]Cookie, len(res))
+	for i, cookie := range res {
+		cookies[i] = Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expires:  cookie.Expiry,
+			HTTPOnly: cookie.HTTPOnly,
+			Secure:   cookie.Secure,
+			SameSite: cookie.SameSite,
+		}
+	}
+	return cookies, nil
+}
+
+// SetCookies adds cookies, scoping those without a domain to the current page
+func (b *webDriverBrowser) SetCookies(cookies ...Cookie) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	for _, cookie := range cookies {
+		err := b.session.AddCookie(context.Background(), webdriver.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Expiry:   cookie.Expires,
+			HTTPOnly: cookie.HTTPOnly,
+			Secure:   cookie.Secure,
+			SameSite: cookie.SameSite,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set cookie %q: %w", cookie.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeleteCookies removes the named cookies visible to the current page, or all of them when no name is given
+func (b *webDriverBrowser) DeleteCookies(names ...string) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		if err := b.session.DeleteAllCookies(context.Background()); err != nil {
+			return fmt.Errorf("failed to delete cookies: %w", err)
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := b.session.DeleteCookie(context.Background(), name); err != nil && !errors.Is(err, webdriver.ErrNoSuchCookie) {
+			return fmt.Errorf("failed to delete cookie %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// StorageItem reads a key from local or session storage of the current page
+func (b *webDriverBrowser) StorageItem(area StorageArea, key string) (string, bool, error) {
+	return storageItem(b, area, key)
+}
+
+// StorageItems reads all keys from local or session storage of the current page
+func (b *webDriverBrowser) StorageItems(area StorageArea) (map[string]string, error) {
+	return storageItems(b, area)
+}
+
+// SetStorageItem writes a key into local or session storage of the current page
+func (b *webDriverBrowser) SetStorageItem(area StorageArea, key, value string) error {
+	return setStorageItem(b, area, key, value)
+}
+
+// ClearStorage removes all keys from local or session storage of the current page
+func (b *webDriverBrowser) ClearStorage(area StorageArea) error {
+	return clearStorage(b, area)
+}
+
+// contains reports whether the list holds the value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
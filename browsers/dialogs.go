@@ -0,0 +1,164 @@
+package browsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// DialogType is the kind of a JavaScript dialog
+type DialogType string
+
+// JavaScript dialog types. WebDriver does not report the type, its dialogs have an empty one.
+const (
+	DialogAlert        DialogType = "alert"
+	DialogConfirm      DialogType = "confirm"
+	DialogPrompt       DialogType = "prompt"
+	DialogBeforeUnload DialogType = "beforeunload"
+)
+
+// Dialog is a JavaScript alert, confirm, prompt or beforeunload dialog opened by the page
+type Dialog struct {
+	Type         DialogType
+	Message      string
+	DefaultValue string
+}
+
+// DialogResponse tells how a dialog is closed
+type DialogResponse struct {
+	Accept bool
+	// PromptText is entered into prompts before they are accepted
+	PromptText string
+}
+
+// DialogHandler decides how to close a dialog
+type DialogHandler func(Dialog) DialogResponse
+
+// AcceptDialogs accepts every dialog
+func AcceptDialogs(Dialog) DialogResponse {
+	return DialogResponse{Accept: true}
+}
+
+// DismissDialogs dismisses every dialog, which is the default when no handler is set
+func DismissDialogs(Dialog) DialogResponse {
+	return DialogResponse{}
+}
+
+// AnswerPrompt accepts every dialog, entering the text into prompts
+func AnswerPrompt(text string) DialogHandler {
+	return func(Dialog) DialogResponse {
+		return DialogResponse{Accept: true, PromptText: text}
+	}
+}
+
+// dialogState holds the dialog handler and the dialogs handled so far.
+// Chrome handles dialogs from event goroutines, so access is guarded.
+type dialogState struct {
+	mu      sync.Mutex
+	handler DialogHandler
+	handled []Dialog
+}
+
+func (s *dialogState) setHandler(handler DialogHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
+
+// respond records the dialog and asks the handler how to close it
+func (s *dialogState) respond(dialog Dialog) DialogResponse {
+	s.mu.Lock()
+	s.handled = append(s.handled, dialog)
+	handler := s.handler
+	s.mu.Unlock()
+
+	if handler == nil {
+		handler = DismissDialogs
+	}
+	return handler(dialog)
+}
+
+func (s *dialogState) list() []Dialog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Dialog(nil), s.handled...)
+}
+
+// OnDialog sets how dialogs opened by any page are closed, nil restores dismissing them
+func (c *Chrome) OnDialog(handler DialogHandler) {
+	c.dialogs.setHandler(handler)
+}
+
+// Dialogs returns the dialogs handled so far, oldest first
+func (c *Chrome) Dialogs() []Dialog {
+	return c.dialogs.list()
+}
+
+// handleDialogs answers the dialogs of every page in the browser context until Close
+func (c *Chrome) handleDialogs() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopDialogs = cancel
+	var mu sync.Mutex
+	handled := map[proto.TargetTargetID]bool{}
+	err := c.watchPages(ctx, func(page *rod.Page) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !handled[page.TargetID] {
+			handled[page.TargetID] = true
+			c.handlePageDialogs(ctx, page)
+		}
+		return nil
+	})
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to handle dialogs: %w", err)
+	}
+	return nil
+}
+
+// handlePageDialogs answers the dialogs of the page until ctx is done
+func (c *Chrome) handlePageDialogs(ctx context.Context, page *rod.Page) {
+	// Dialogs block the page until answered, so they are handled as soon as they open
+	wait := page.Context(ctx).EachEvent(func(e *proto.PageJavascriptDialogOpening) {
+		res := c.dialogs.respond(Dialog{Type: DialogType(e.Type), Message: e.Message, DefaultValue: e.DefaultPrompt})
+		// Fails only when the page was closed together with its dialog
+		proto.PageHandleJavaScriptDialog{Accept: res.Accept, PromptText: res.PromptText}.Call(page)
+	})
+	go wait()
+}
+
+// OnDialog sets how dialogs are closed, nil restores dismissing them.
+// WebDriver has no dialog events, a dialog is handled by the next command that runs into it.
+func (b *webDriverBrowser) OnDialog(handler DialogHandler) {
+	b.dialogs.setHandler(handler)
+}
+
+// Dialogs returns the dialogs handled so far, oldest first
+func (b *webDriverBrowser) Dialogs() []Dialog {
+	return b.dialogs.list()
+}
+
+// handlePrompt closes the open user prompt as the dialog handler decides
+func (b *webDriverBrowser) handlePrompt(ctx context.Context, session *webdriver.Session) error {
+	message, err := session.AlertText(ctx)
+	if err != nil {
+		return err
+	}
+	res := b.dialogs.respond(Dialog{Message: message})
+	if !res.Accept {
+		return session.DismissAlert(ctx)
+	}
+	if res.PromptText != "" {
+		// Alerts and confirms have no text field to fill
+		err := session.SendAlertText(ctx, res.PromptText)
+		if err != nil && !errors.Is(err, webdriver.ErrElementNotInteractable) && !errors.Is(err, webdriver.ErrUnsupportedOperation) {
+			return fmt.Errorf("failed to answer prompt: %w", err)
+		}
+	}
+	return session.AcceptAlert(ctx)
+}
@@ -0,0 +1,225 @@
+package browsers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Download is a file saved by the browser
+type Download struct {
+	// Path is where the file was saved
+	Path string
+	// Filename is the name the page suggested for the file
+	Filename string
+	// Size is the file size in bytes
+	Size int64
+}
+
+// SetFiles selects the files of an <input type=file>
+func (e *chromeElement) SetFiles(paths ...string) error {
+	return chromeError(e.el.SetFiles(paths))
+}
+
+// SetFiles selects the files of an <input type=file>
+func (e *webDriverElement) SetFiles(paths ...string) error {
+	abs, err := absolutePaths(paths)
+	if err != nil {
+		return err
+	}
+	// Drivers take the file paths as keys, one path per line
+	return e.el.SendKeys(e.ctx, strings.Join(abs, "\n"))
+}
+
+// absolutePaths resolves the paths against the working directory, as the browser does not share it
+func absolutePaths(paths []string) ([]string, error) {
+	abs := make([]string, len(paths))
+	for i, path := range paths {
+		var err error
+		if abs[i], err = filepath.Abs(path); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+	}
+	return abs, nil
+}
+
+// newDownload describes the saved file
+func newDownload(path, filename string) (Download, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Download{}, fmt.Errorf("failed to read download: %w", err)
+	}
+	return Download{Path: path, Filename: filename, Size: info.Size()}, nil
+}
+
+// uniquePath returns a path in dir for the file name that does not exist yet, numbering it like browsers do
+func uniquePath(dir, name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	path := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+}
+
+// WaitForDownload runs the action and waits for the download it starts to finish.
+// Files are saved into the configured download directory, or a temporary one removed on Close.
+func (c *Chrome) WaitForDownload(action func() error) (Download, error) {
+	if c.Browser == nil {
+		return Download{}, fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	dir := c.Options.DownloadDir
+	if dir == "" {
+		if c.downloadDir == "" {
+			temp, err := tempDownloadDir()
+			if err != nil {
+				return Download{}, err
+			}
+			c.downloadDir = temp
+		}
+		dir = c.downloadDir
+	}
+
+	// Files are saved under their GUID while waiting, so the finished one can be found and named reliably
+	err := proto.BrowserSetDownloadBehavior{
+		Behavior:         proto.BrowserSetDownloadBehaviorBehaviorAllowAndName,
+		BrowserContextID: c.Browser.BrowserContextID,
+		DownloadPath:     dir,
+		EventsEnabled:    true,
+	}.Call(c.Browser)
+	if err != nil {
+		return Download{}, fmt.Errorf("failed to set download directory: %w", chromeError(err))
+	}
+	defer c.restoreDownloadBehavior()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.wait.withDefaults().Timeout)
+	defer cancel()
+
+	var begin proto.BrowserDownloadWillBegin
+	var state proto.BrowserDownloadProgressState
+	wait := c.Browser.Context(ctx).EachEvent(func(e *proto.BrowserDownloadWillBegin) {
+		if begin.GUID == "" {
+			begin = *e
+		}
+	}, func(e *proto.BrowserDownloadProgress) bool {
+		if e.GUID != begin.GUID || e.State == proto.BrowserDownloadProgressStateInProgress {
+			return false
+		}
+		state = e.State
+		return true
+	})
+
+	if err := action(); err != nil {
+		cancel()
+		wait()
+		return Download{}, err
+	}
+	wait()
+
+	switch {
+	case state == proto.BrowserDownloadProgressStateCanceled:
+		return Download{}, fmt.Errorf("download of %s was canceled", begin.SuggestedFilename)
+	case state == "":
+		return Download{}, fmt.Errorf("waiting for download: %w", ErrTimeout)
+	}
+
+	filename := begin.SuggestedFilename
+	if filename == "" {
+		filename = begin.GUID
+	}
+	path := uniquePath(dir, filename)
+	if err := os.Rename(filepath.Join(dir, begin.GUID), path); err != nil {
+		return Download{}, fmt.Errorf("failed to name download: %w", err)
+	}
+	return newDownload(path, filename)
+}
+
+// restoreDownloadBehavior returns to saving downloads as configured at launch
+func (c *Chrome) restoreDownloadBehavior() {
+	if c.Options.DownloadDir != "" {
+		c.setDownloadDir(c.Browser)
+		return
+	}
+	proto.BrowserSetDownloadBehavior{
+		Behavior:         proto.BrowserSetDownloadBehaviorBehaviorDefault,
+		BrowserContextID: c.Browser.BrowserContextID,
+	}.Call(c.Browser)
+}
+
+// WaitForDownload runs the action and waits for the download it starts to finish.
+// Files are saved into the configured download directory, or a temporary one removed on Close.
+// Safari offers no control over downloads and reports ErrUnsupported.
+func (b *webDriverBrowser) WaitForDownload(action func() error) (Download, error) {
+	if err := b.checkSession(); err != nil {
+		return Download{}, err
+	}
+	if b.downloadDir == "" {
+		return Download{}, fmt.Errorf("downloads are not supported by %s: %w", b.name, ErrUnsupported)
+	}
+
+	before, err := os.ReadDir(b.downloadDir)
+	if err != nil {
+		return Download{}, fmt.Errorf("failed to read download directory: %w", err)
+	}
+	known := make(map[string]bool, len(before))
+	for _, entry := range before {
+		known[entry.Name()] = true
+	}
+
+	if err := action(); err != nil {
+		return Download{}, err
+	}
+
+	// Firefox writes into a .part file next to the download and removes it once the download completes
+	var name string
+	var size int64 = -1
+	err = poll(context.Background(), b.wait, func() (bool, error) {
+		entries, err := os.ReadDir(b.downloadDir)
+		if err != nil {
+			return false, err
+		}
+		name = ""
+		partial := false
+		for _, entry := range entries {
+			switch {
+			case known[entry.Name()]:
+			case strings.HasSuffix(entry.Name(), ".part"):
+				partial = true
+			case name == "":
+				name = entry.Name()
+			}
+		}
+		if name == "" || partial {
+			return false, nil
+		}
+
+		info, err := os.Stat(filepath.Join(b.downloadDir, name))
+		if err != nil {
+			return false, nil
+		}
+		// Wait for one more poll with an unchanged size before treating the file as complete
+		stable := info.Size() == size
+		size = info.Size()
+		return stable, nil
+	})
+	if err != nil {
+		return Download{}, fmt.Errorf("waiting for download: %w", err)
+	}
+	return newDownload(filepath.Join(b.downloadDir, name), name)
+}
+
+// tempDownloadDir creates a directory for downloads that is removed when the browser closes
+func tempDownloadDir() (string, error) {
+	dir, err := os.MkdirTemp("", "owl-downloads-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,24 @@
+package browsers
+
+// Element interface to unify element handling across browsers
+type Element interface {
+	Click() error
+	Type(text string) error
+	Text() (string, error)
+	Attribute(name string) (string, error)
+	Visible() (bool, error)
+	Enabled() (bool, error)
+	Screenshot() ([]byte, error)
+	SetFiles(paths ...string) error
+}
+
+// unwrapElement returns the browser's own element behind a wrapper, such as the elements a Tracer finds
+func unwrapElement(el Element) Element {
+	for {
+		wrapper, ok := el.(interface{ Unwrap() Element })
+		if !ok {
+			return el
+		}
+		el = wrapper.Unwrap()
+	}
+}
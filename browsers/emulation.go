@@ -0,0 +1,201 @@
+package browsers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/devices"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/config"
+)
+
+// Viewport is the size of the page content area in CSS pixels
+type Viewport struct {
+	Width  int
+	Height int
+}
+
+// Device describes the screen and browser of an emulated device.
+// Zero values keep the browser's own settings.
+type Device struct {
+	Viewport Viewport
+	// DeviceScaleFactor is the number of device pixels per CSS pixel
+	DeviceScaleFactor float64
+	// IsMobile makes the page honour the meta viewport tag and lay out like on a phone
+	IsMobile  bool
+	HasTouch  bool
+	UserAgent string
+}
+
+// Landscape returns the device turned sideways
+func (d Device) Landscape() Device {
+	if d.Viewport.Width < d.Viewport.Height {
+		d.Viewport.Width, d.Viewport.Height = d.Viewport.Height, d.Viewport.Width
+	}
+	return d
+}
+
+const (
+	iOSUserAgent     = "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1"
+	iPadUserAgent    = "Mozilla/5.0 (iPad; CPU OS 16_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.0 Mobile/15E148 Safari/604.1"
+	androidUserAgent = "Mozilla/5.0 (Linux; Android 14; %s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 %sSafari/537.36"
+)
+
+// Devices are the built-in device descriptors by name, in portrait orientation
+var Devices = map[string]Device{
+	"iPhone SE":         {Viewport{375, 667}, 2, true, true, iOSUserAgent},
+	"iPhone 14":         {Viewport{390, 664}, 3, true, true, iOSUserAgent},
+	"iPhone 14 Plus":    {Viewport{428, 746}, 3, true, true, iOSUserAgent},
+	"iPhone 14 Pro":     {Viewport{393, 660}, 3, true, true, iOSUserAgent},
+	"iPhone 14 Pro Max": {Viewport{430, 740}, 3, true, true, iOSUserAgent},
+	"iPad Mini":         {Viewport{768, 1024}, 2, true, true, iPadUserAgent},
+	"iPad Pro 11":       {Viewport{834, 1194}, 2, true, true, iPadUserAgent},
+	"Pixel 5":           {Viewport{393, 727}, 2.75, true, true, fmt.Sprintf(androidUserAgent, "Pixel 5", "Mobile ")},
+	"Pixel 7":           {Viewport{412, 839}, 2.625, true, true, fmt.Sprintf(androidUserAgent, "Pixel 7", "Mobile ")},
+	"Galaxy S9+":        {Viewport{320, 658}, 4.5, true, true, fmt.Sprintf(androidUserAgent, "SM-G965U", "Mobile ")},
+	"Galaxy Tab S4":     {Viewport{712, 1138}, 2.25, true, true, fmt.Sprintf(androidUserAgent, "SM-T837A", "")},
+	"Desktop":           {Viewport: Viewport{1280, 720}, DeviceScaleFactor: 1},
+	"Desktop HiDPI":     {Viewport: Viewport{1280, 720}, DeviceScaleFactor: 2},
+}
+
+// LookupDevice returns the built-in device descriptor with the name
+func LookupDevice(name string) (Device, error) {
+	device, ok := Devices[name]
+	if !ok {
+		names := make([]string, 0, len(Devices))
+		for known := range Devices {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return Device{}, fmt.Errorf("unknown device %q, known devices are %q", name, names)
+	}
+	return device, nil
+}
+
+// deviceFromConfig builds the device of the emulation option, the zero Device when none is configured
+func deviceFromConfig(opts config.Emulation) (Device, error) {
+	var device Device
+	if opts.Device != "" {
+		var err error
+		if device, err = LookupDevice(opts.Device); err != nil {
+			return Device{}, err
+		}
+	}
+	if opts.Viewport.Width > 0 && opts.Viewport.Height > 0 {
+		device.Viewport = Viewport{Width: opts.Viewport.Width, Height: opts.Viewport.Height}
+	}
+	if opts.DeviceScaleFactor > 0 {
+		device.DeviceScaleFactor = opts.DeviceScaleFactor
+	}
+	if opts.IsMobile != nil {
+		device.IsMobile = *opts.IsMobile
+	}
+	if opts.HasTouch != nil {
+		device.HasTouch = *opts.HasTouch
+	}
+	if opts.UserAgent != "" {
+		device.UserAgent = opts.UserAgent
+	}
+	return device, nil
+}
+
+// Emulate makes every page of the browser context, open and opened later, emulate the device
+func (c *Chrome) Emulate(device Device) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	emulation, err := c.chromeDevice(device)
+	if err != nil {
+		return fmt.Errorf("failed to emulate device: %w", err)
+	}
+
+	// Rod applies its default device to the pages it opens, which would undo the emulation
+	c.Browser.DefaultDevice(emulation)
+	if c.stopEmulation != nil {
+		c.stopEmulation()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopEmulation = cancel
+	// Emulating a page twice sets the same overrides again, so attaching needs no bookkeeping
+	err = c.watchPages(ctx, func(page *rod.Page) error {
+		return chromeError(page.Emulate(emulation))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to emulate device: %w", err)
+	}
+	return nil
+}
+
+// chromeDevice translates the device into Rod's descriptor, whose Emulate calls
+// Emulation.setDeviceMetricsOverride, Emulation.setTouchEmulationEnabled and Network.setUserAgentOverride
+func (c *Chrome) chromeDevice(device Device) (devices.Device, error) {
+	userAgent := device.UserAgent
+	if userAgent == "" {
+		// An empty override would send no user agent at all
+		version, err := proto.BrowserGetVersion{}.Call(c.Browser)
+		if err != nil {
+			return devices.Device{}, chromeError(err)
+		}
+		userAgent = version.UserAgent
+	}
+
+	size := devices.ScreenSize{Width: device.Viewport.Width, Height: device.Viewport.Height}
+	emulation := devices.Device{
+		Title:     "owl",
+		UserAgent: userAgent,
+		Screen:    devices.Screen{DevicePixelRatio: device.DeviceScaleFactor, Horizontal: size, Vertical: size},
+	}
+	if device.IsMobile {
+		emulation.Capabilities = append(emulation.Capabilities, "mobile")
+	}
+	if device.HasTouch {
+		emulation.Capabilities = append(emulation.Capabilities, "touch")
+	}
+	if size.Width > size.Height {
+		emulation = emulation.Landscape()
+	}
+	return emulation, nil
+}
+
+// Emulate resizes the window so the page content area gets the device viewport.
+// The user agent, scale factor and touch support are fixed when the session starts,
+// so devices differing from the emulation launch option in those are refused.
+func (b *webDriverBrowser) Emulate(device Device) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	launched := b.device
+	launched.Viewport = device.Viewport
+	if device != launched {
+		return fmt.Errorf("%s takes the user agent, scale factor and touch support of a device only from the emulation launch option: %w", b.name, ErrUnsupported)
+	}
+	if err := b.setViewport(context.Background(), device.Viewport); err != nil {
+		return fmt.Errorf("failed to emulate device: %w", err)
+	}
+	return nil
+}
+
+// setViewport resizes the window by the viewport's difference to the current content area
+func (b *webDriverBrowser) setViewport(ctx context.Context, viewport Viewport) error {
+	if viewport.Width <= 0 || viewport.Height <= 0 {
+		return nil
+	}
+	var inner struct {
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	}
+	script := `return {width: window.innerWidth, height: window.innerHeight}`
+	if err := b.session.ExecuteScript(ctx, script, nil, &inner); err != nil {
+		return err
+	}
+	rect, err := b.session.WindowRect(ctx)
+	if err != nil {
+		return err
+	}
+	rect.Width += float64(viewport.Width) - inner.Width
+	rect.Height += float64(viewport.Height) - inner.Height
+	_, err = b.session.SetWindowRect(ctx, *rect)
+	return err
+}
@@ -0,0 +1,333 @@
+package browsers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/config"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Geolocation is a position in degrees, Accuracy is in meters
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64
+}
+
+// Permission is a browser permission by its Permissions API name
+type Permission string
+
+// Permissions that can be granted and denied
+const (
+	PermissionGeolocation    Permission = "geolocation"
+	PermissionNotifications  Permission = "notifications"
+	PermissionClipboardRead  Permission = "clipboard-read"
+	PermissionClipboardWrite Permission = "clipboard-write"
+	PermissionCamera         Permission = "camera"
+	PermissionMicrophone     Permission = "microphone"
+)
+
+// ColorScheme is a value of the prefers-color-scheme media feature
+type ColorScheme string
+
+// Color schemes
+const (
+	ColorSchemeLight        ColorScheme = "light"
+	ColorSchemeDark         ColorScheme = "dark"
+	ColorSchemeNoPreference ColorScheme = "no-preference"
+)
+
+// ReducedMotion is a value of the prefers-reduced-motion media feature
+type ReducedMotion string
+
+// Reduced motion preferences
+const (
+	ReducedMotionReduce       ReducedMotion = "reduce"
+	ReducedMotionNoPreference ReducedMotion = "no-preference"
+)
+
+// MediaFeatures are the emulated CSS media features, empty fields keep the browser's own value
+type MediaFeatures struct {
+	ColorScheme   ColorScheme
+	ReducedMotion ReducedMotion
+}
+
+// chromePermissions maps the permissions onto the types Browser.grantPermissions takes
+var chromePermissions = map[Permission]proto.BrowserPermissionType{
+	PermissionGeolocation:    proto.BrowserPermissionTypeGeolocation,
+	PermissionNotifications:  proto.BrowserPermissionTypeNotifications,
+	PermissionClipboardRead:  proto.BrowserPermissionTypeClipboardReadWrite,
+	PermissionClipboardWrite: proto.BrowserPermissionTypeClipboardSanitizedWrite,
+	PermissionCamera:         proto.BrowserPermissionTypeVideoCapture,
+	PermissionMicrophone:     proto.BrowserPermissionTypeAudioCapture,
+}
+
+// pageEnvironment is what Chrome emulates on every page besides the device
+type pageEnvironment struct {
+	geolocation *Geolocation
+	timezone    string
+	locale      string
+	media       MediaFeatures
+}
+
+// apply sets the environment on the page, clearing the overrides left empty
+func (env pageEnvironment) apply(page *rod.Page) error {
+	if env.geolocation != nil {
+		location := *env.geolocation
+		err := proto.EmulationSetGeolocationOverride{
+			Latitude:  &location.Latitude,
+			Longitude: &location.Longitude,
+			Accuracy:  &location.Accuracy,
+		}.Call(page)
+		if err != nil {
+			return chromeError(err)
+		}
+	} else if err := (proto.EmulationClearGeolocationOverride{}).Call(page); err != nil {
+		return chromeError(err)
+	}
+
+	// Timezone and locale overrides belong to the renderer process, which pages may share.
+	// The page holding the override already applies it to the others.
+	err := proto.EmulationSetTimezoneOverride{TimezoneID: env.timezone}.Call(page)
+	if err != nil && !strings.Contains(err.Error(), "Timezone override is already in effect") {
+		return chromeError(err)
+	}
+	err = proto.EmulationSetLocaleOverride{Locale: env.locale}.Call(page)
+	if err != nil && !strings.Contains(err.Error(), "Another locale override is already in effect") {
+		return chromeError(err)
+	}
+
+	return chromeError(proto.EmulationSetEmulatedMedia{Features: []*proto.EmulationMediaFeature{
+		{Name: "prefers-color-scheme", Value: string(env.media.ColorScheme)},
+		{Name: "prefers-reduced-motion", Value: string(env.media.ReducedMotion)},
+	}}.Call(page))
+}
+
+// environmentFromConfig builds the environment of the launch options, timezone and locale are set on the process instead
+func environmentFromConfig(opts config.LaunchConfig) pageEnvironment {
+	env := pageEnvironment{media: MediaFeatures{
+		ColorScheme:   ColorScheme(opts.ColorScheme),
+		ReducedMotion: ReducedMotion(opts.ReducedMotion),
+	}}
+	if opts.Geolocation != nil {
+		env.geolocation = &Geolocation{
+			Latitude:  opts.Geolocation.Latitude,
+			Longitude: opts.Geolocation.Longitude,
+			Accuracy:  opts.Geolocation.Accuracy,
+		}
+	}
+	return env
+}
+
+// SetGeolocation makes the pages report the position, nil restores the real one.
+// Pages only read it once PermissionGeolocation is granted.
+func (c *Chrome) SetGeolocation(location *Geolocation) error {
+	c.environment.geolocation = location
+	return c.emulateEnvironment("geolocation")
+}
+
+// SetTimezone makes the pages use the IANA timezone, such as "Europe/Berlin", empty restores the system one
+func (c *Chrome) SetTimezone(timezone string) error {
+	c.environment.timezone = timezone
+	return c.emulateEnvironment("timezone")
+}
+
+// SetLocale makes the pages use the locale, such as "de-DE", for formatting and navigator.language
+func (c *Chrome) SetLocale(locale string) error {
+	c.environment.locale = locale
+	return c.emulateEnvironment("locale")
+}
+
+// EmulateMedia makes the pages match the CSS media features
+func (c *Chrome) EmulateMedia(features MediaFeatures) error {
+	c.environment.media = features
+	return c.emulateEnvironment("media features")
+}
+
+// emulateEnvironment applies the environment to every page of the browser context, open and opened later
+func (c *Chrome) emulateEnvironment(setting string) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	if c.stopEnvironment != nil {
+		c.stopEnvironment()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopEnvironment = cancel
+	// Applying the environment twice sets the same overrides again, so attaching needs no bookkeeping
+	if err := c.watchPages(ctx, c.environment.apply); err != nil {
+		return fmt.Errorf("failed to emulate %s: %w", setting, err)
+	}
+	return nil
+}
+
+// GrantPermissions grants the permissions to the origin, such as "https://example.com", or to every origin when empty
+func (c *Chrome) GrantPermissions(origin string, permissions ...Permission) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	types := make([]proto.BrowserPermissionType, len(permissions))
+	for i, permission := range permissions {
+		permissionType, ok := chromePermissions[permission]
+		if !ok {
+			return fmt.Errorf("unknown permission %q: %w", permission, ErrInvalidArgument)
+		}
+		types[i] = permissionType
+	}
+	err := proto.BrowserGrantPermissions{
+		Permissions:      types,
+		Origin:           origin,
+		BrowserContextID: c.Browser.BrowserContextID,
+	}.Call(c.Browser)
+	if err != nil {
+		return fmt.Errorf("failed to grant permissions: %w", chromeError(err))
+	}
+	return nil
+}
+
+// DenyPermissions denies the permissions to the origin, or to every origin when empty
+func (c *Chrome) DenyPermissions(origin string, permissions ...Permission) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	for _, permission := range permissions {
+		err := proto.BrowserSetPermission{
+			Permission:       &proto.BrowserPermissionDescriptor{Name: string(permission)},
+			Setting:          proto.BrowserPermissionSettingDenied,
+			Origin:           origin,
+			BrowserContextID: c.Browser.BrowserContextID,
+		}.Call(c.Browser)
+		if err != nil {
+			return fmt.Errorf("failed to deny permission %q: %w", permission, chromeError(err))
+		}
+	}
+	return nil
+}
+
+// ResetPermissions makes the pages ask for every permission again
+func (c *Chrome) ResetPermissions() error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	err := proto.BrowserResetPermissions{BrowserContextID: c.Browser.BrowserContextID}.Call(c.Browser)
+	if err != nil {
+		return fmt.Errorf("failed to reset permissions: %w", chromeError(err))
+	}
+	return nil
+}
+
+// permissionKey is a permission set for an origin through BiDi
+type permissionKey struct {
+	origin     string
+	permission Permission
+}
+
+// SetGeolocation makes the pages report the position, nil restores the real one.
+// Pages only read it once PermissionGeolocation is granted.
+func (b *webDriverBrowser) SetGeolocation(location *Geolocation) error {
+	if err := b.checkEmulation("geolocation emulation"); err != nil {
+		return err
+	}
+	var coordinates *webdriver.GeolocationCoordinates
+	if location != nil {
+		coordinates = &webdriver.GeolocationCoordinates{
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+			Accuracy:  location.Accuracy,
+		}
+	}
+	if err := b.bidi.SetGeolocationOverride(context.Background(), coordinates); err != nil {
+		return fmt.Errorf("failed to emulate geolocation: %w", err)
+	}
+	return nil
+}
+
+// SetTimezone makes the pages use the IANA timezone, such as "Europe/Berlin", empty restores the system one
+func (b *webDriverBrowser) SetTimezone(timezone string) error {
+	if err := b.checkEmulation("timezone emulation"); err != nil {
+		return err
+	}
+	if err := b.bidi.SetTimezoneOverride(context.Background(), timezone); err != nil {
+		return fmt.Errorf("failed to emulate timezone: %w", err)
+	}
+	return nil
+}
+
+// SetLocale makes the pages use the locale, such as "de-DE", for formatting and navigator.language
+func (b *webDriverBrowser) SetLocale(locale string) error {
+	if err := b.checkEmulation("locale emulation"); err != nil {
+		return err
+	}
+	if err := b.bidi.SetLocaleOverride(context.Background(), locale); err != nil {
+		return fmt.Errorf("failed to emulate locale: %w", err)
+	}
+	return nil
+}
+
+// EmulateMedia is unsupported, the WebDriver browsers read the media features only from their launch preferences
+func (b *webDriverBrowser) EmulateMedia(features MediaFeatures) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s takes the color scheme and reduced motion only from the launch options: %w", b.name, ErrUnsupported)
+}
+
+// GrantPermissions grants the permissions to the origin, such as "https://example.com".
+// BiDi sets permissions per origin, so the origin is required.
+func (b *webDriverBrowser) GrantPermissions(origin string, permissions ...Permission) error {
+	return b.setPermissions(origin, permissions, webdriver.PermissionGranted)
+}
+
+// DenyPermissions denies the permissions to the origin, which is required
+func (b *webDriverBrowser) DenyPermissions(origin string, permissions ...Permission) error {
+	return b.setPermissions(origin, permissions, webdriver.PermissionDenied)
+}
+
+// ResetPermissions makes the pages ask again for the permissions granted or denied so far
+func (b *webDriverBrowser) ResetPermissions() error {
+	if err := b.checkEmulation("setting permissions"); err != nil {
+		return err
+	}
+	for key := range b.permissions {
+		err := b.bidi.SetPermission(context.Background(), string(key.permission), webdriver.PermissionPrompt, key.origin)
+		if err != nil {
+			return fmt.Errorf("failed to reset permission %q: %w", key.permission, err)
+		}
+		delete(b.permissions, key)
+	}
+	return nil
+}
+
+func (b *webDriverBrowser) setPermissions(origin string, permissions []Permission, state string) error {
+	if err := b.checkEmulation("setting permissions"); err != nil {
+		return err
+	}
+	if origin == "" {
+		return fmt.Errorf("%s sets permissions per origin, an origin is required: %w", b.name, ErrInvalidArgument)
+	}
+	for _, permission := range permissions {
+		if err := b.bidi.SetPermission(context.Background(), string(permission), state, origin); err != nil {
+			return fmt.Errorf("failed to set permission %q: %w", permission, err)
+		}
+		if b.permissions == nil {
+			b.permissions = map[permissionKey]bool{}
+		}
+		b.permissions[permissionKey{origin, permission}] = true
+	}
+	return nil
+}
+
+// checkEmulation reports an error if there is no session or no BiDi connection to emulate through
+func (b *webDriverBrowser) checkEmulation(feature string) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if b.bidi == nil {
+		return fmt.Errorf("%s needs WebDriver BiDi, which %s does not offer: %w", feature, b.name, ErrUnsupported)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package browsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Errors reported by all browsers, usable with errors.Is.
+// WebDriver failures carry the decoded W3C payload as *webdriver.Error.
+var (
+	ErrNoSuchElement           = webdriver.ErrNoSuchElement
+	ErrStaleElementReference   = webdriver.ErrStaleElementReference
+	ErrElementNotInteractable  = webdriver.ErrElementNotInteractable
+	ErrElementClickIntercepted = webdriver.ErrElementClickIntercepted
+	ErrInvalidSelector         = webdriver.ErrInvalidSelector
+	ErrInvalidArgument         = webdriver.ErrInvalidArgument
+	ErrTimeout                 = webdriver.ErrTimeout
+	ErrInvalidSessionID        = webdriver.ErrInvalidSessionID
+	ErrUnexpectedAlertOpen     = webdriver.ErrUnexpectedAlertOpen
+	ErrNoSuchAlert             = webdriver.ErrNoSuchAlert
+	ErrNoSuchFrame             = webdriver.ErrNoSuchFrame
+	ErrNoSuchWindow            = webdriver.ErrNoSuchWindow
+	ErrNoSuchShadowRoot        = webdriver.ErrNoSuchShadowRoot
+	ErrJavascriptError         = webdriver.ErrJavascriptError
+	ErrUnsupported             = webdriver.ErrUnsupportedOperation
+)
+
+// chromeError maps Rod and CDP errors onto the shared error kinds, keeping the original error in the chain
+func chromeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var cdpErr *cdp.Error
+	switch {
+	case strings.Contains(err.Error(), "is not a valid selector"):
+		return fmt.Errorf("%w: %w", ErrInvalidSelector, err)
+	case isError[*rod.ElementNotFoundError](err):
+		return fmt.Errorf("%w: %w", ErrNoSuchElement, err)
+	case isError[*rod.CoveredError](err):
+		return fmt.Errorf("%w: %w", ErrElementClickIntercepted, err)
+	case isError[*rod.NotInteractableError](err):
+		return fmt.Errorf("%w: %w", ErrElementNotInteractable, err)
+	case isError[*rod.NoShadowRootError](err):
+		return fmt.Errorf("%w: %w", ErrNoSuchShadowRoot, err)
+	case isError[*rod.EvalError](err):
+		return fmt.Errorf("%w: %w", ErrJavascriptError, err)
+	case isError[*rod.ObjectNotFoundError](err),
+		errors.Is(err, cdp.ErrObjNotFound),
+		errors.Is(err, cdp.ErrCtxNotFound),
+		errors.Is(err, cdp.ErrCtxDestroyed):
+		return fmt.Errorf("%w: %w", ErrStaleElementReference, err)
+	case isError[*rod.PageNotFoundError](err), errors.Is(err, cdp.ErrSessionNotFound):
+		return fmt.Errorf("%w: %w", ErrInvalidSessionID, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.As(err, &cdpErr):
+		if strings.Contains(cdpErr.Message, "Node is detached") || strings.Contains(cdpErr.Message, "No node with given id") {
+			return fmt.Errorf("%w: %w", ErrStaleElementReference, err)
+		}
+	}
+	return err
+}
+
+// isError reports whether err's chain contains an error of type T
+func isError[T error](err error) bool {
+	var target T
+	return errors.As(err, &target)
+}
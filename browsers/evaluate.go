@@ -0,0 +1,183 @@
+package browsers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Scripts passed to Evaluate are JavaScript function expressions such as
+// "(a, b) => a + b", called with the given arguments. Elements may be passed
+// as arguments and are returned as Element, alone or inside arrays and objects.
+// Other values are decoded like encoding/json decodes into interface{}.
+
+// chromeNodeKey marks DOM nodes in values serialized from Chrome
+const chromeNodeKey = "__owlNode"
+
+// chromeEvalScript runs the user function and replaces DOM nodes in its result with references into a node list
+const chromeEvalScript = `async (fn, ...args) => {
+	const nodes = [];
+	const encode = (v) => {
+		if (v instanceof Node) {
+			nodes.push(v);
+			return {"` + chromeNodeKey + `": nodes.length - 1};
+		}
+		if (Array.isArray(v)) return v.map(encode);
+		if (v && typeof v === "object" && Object.getPrototypeOf(v) === Object.prototype) {
+			return Object.fromEntries(Object.entries(v).map(([k, x]) => [k, encode(x)]));
+		}
+		return v === undefined ? null : v;
+	};
+	return {value: encode(await fn(...args)), nodes};
+}`
+
+// Evaluate runs the script in the current page and returns its result, awaiting returned promises
+func (c *Chrome) Evaluate(script string, args ...interface{}) (interface{}, error) {
+	return c.evaluate(script, args)
+}
+
+// EvaluateAsync runs the script with a completion callback appended to its arguments
+// and returns the value the callback is called with
+func (c *Chrome) EvaluateAsync(script string, args ...interface{}) (interface{}, error) {
+	return c.evaluate(fmt.Sprintf("(...args) => new Promise((done) => (%s)(...args, done))", trimScript(script)), args)
+}
+
+func (c *Chrome) evaluate(script string, args []interface{}) (interface{}, error) {
+	if c.Page == nil {
+		return nil, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+
+	jsArgs := []interface{}{}
+	for _, arg := range args {
+		if el, ok := arg.(Element); ok {
+			arg = unwrapElement(el)
+		}
+		if el, ok := arg.(*chromeElement); ok {
+			jsArgs = append(jsArgs, el.el.Object)
+			continue
+		}
+		jsArgs = append(jsArgs, arg)
+	}
+
+	wrapper := fmt.Sprintf("(...args) => (%s)(%s, ...args)", chromeEvalScript, trimScript(script))
+
+	page := c.scope()
+	obj, err := page.Evaluate(rod.Eval(wrapper, jsArgs...).ByObject().ByPromise())
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate script: %w", chromeError(err))
+	}
+	defer page.Release(obj)
+
+	res, err := page.Evaluate(rod.Eval(`function() { return this.value }`).This(obj))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script result: %w", chromeError(err))
+	}
+	data := res.Value.JSON("", "")
+
+	var nodes rod.Elements
+	if strings.Contains(data, chromeNodeKey) {
+		nodes, err = page.ElementsByJS(rod.Eval(`function() { return this.nodes }`).This(obj))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script result: %w", chromeError(err))
+		}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return nil, fmt.Errorf("failed to decode script result: %w", err)
+	}
+	return decodeElements(value, func(ref map[string]interface{}) (Element, bool) {
+		index, ok := ref[chromeNodeKey].(float64)
+		if !ok || int(index) >= len(nodes) {
+			return nil, false
+		}
+		return &chromeElement{el: nodes[int(index)], wait: c.wait, ctx: context.Background()}, true
+	}), nil
+}
+
+// Evaluate runs the script in the current page and returns its result, awaiting returned promises
+func (b *webDriverBrowser) Evaluate(script string, args ...interface{}) (interface{}, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf("return (%s).apply(null, arguments)", trimScript(script))
+
+	var raw json.RawMessage
+	if err := b.session.ExecuteScript(context.Background(), body, webDriverArgs(args), &raw); err != nil {
+		return nil, fmt.Errorf("failed to evaluate script: %w", err)
+	}
+	return b.decodeResult(raw)
+}
+
+// EvaluateAsync runs the script with a completion callback appended to its arguments
+// and returns the value the callback is called with
+func (b *webDriverBrowser) EvaluateAsync(script string, args ...interface{}) (interface{}, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf("(%s).apply(null, arguments)", trimScript(script))
+
+	var raw json.RawMessage
+	if err := b.session.ExecuteAsyncScript(context.Background(), body, webDriverArgs(args), &raw); err != nil {
+		return nil, fmt.Errorf("failed to evaluate script: %w", err)
+	}
+	return b.decodeResult(raw)
+}
+
+// decodeResult converts a script result into Go values, turning web element references into Elements
+func (b *webDriverBrowser) decodeResult(raw json.RawMessage) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode script result: %w", err)
+	}
+	return decodeElements(value, func(ref map[string]interface{}) (Element, bool) {
+		id, ok := ref[webdriver.ElementKey].(string)
+		if !ok {
+			return nil, false
+		}
+		return &webDriverElement{el: b.session.Element(id), browser: b, ctx: context.Background()}, true
+	}), nil
+}
+
+// webDriverArgs replaces Elements in script arguments with their web element references
+func webDriverArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		if el, ok := arg.(Element); ok {
+			arg = unwrapElement(el)
+		}
+		if el, ok := arg.(*webDriverElement); ok {
+			converted[i] = el.el
+			continue
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+// decodeElements walks a decoded JSON value and replaces element references recognized by toElement
+func decodeElements(value interface{}, toElement func(map[string]interface{}) (Element, bool)) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			v[i] = decodeElements(item, toElement)
+		}
+	case map[string]interface{}:
+		if el, ok := toElement(v); ok {
+			return el
+		}
+		for key, item := range v {
+			v[key] = decodeElements(item, toElement)
+		}
+	}
+	return value
+}
+
+// trimScript removes surrounding whitespace and semicolons so the script can be wrapped as an expression
+func trimScript(script string) string {
+	return strings.Trim(script, "\t\n\v\f\r ;")
+}
@@ -1,105 +1,241 @@
 package browsers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"os"
 	"os/exec"
+	"strconv"
 	"time"
+
+	"github.com/valdemart123/go-owl/config"
+	"github.com/valdemart123/go-owl/webdriver"
 )
 
 // Firefox struct using native WebDriver commands
 type Firefox struct {
-	cmd      *exec.Cmd
-	sessionID string
+	webDriverBrowser
+
+	// DriverURL is the address Geckodriver listens on, defaults to webdriver.DefaultURL
+	DriverURL string
+	// Options are the launch options from the config
+	Options config.LaunchConfig
 }
 
 // Launch starts a new Firefox browser instance using Geckodriver
 func (f *Firefox) Launch() error {
+	return f.LaunchContext(context.Background())
+}
+
+// LaunchContext starts a new Firefox instance, giving up when ctx is done
+func (f *Firefox) LaunchContext(ctx context.Context) error {
 	log.Println("Launching Firefox...")
-	f.cmd = exec.Command("geckodriver", "--port=4444")
+	f.name = "Firefox"
+	f.client = webdriver.NewClient(f.DriverURL)
+	f.slowMo = time.Duration(f.Options.SlowMo) * time.Millisecond
+
+	// The emulated device goes into the preferences Firefox starts with
+	device, err := deviceFromConfig(f.Options.Emulation)
+	if err != nil {
+		return err
+	}
+	f.device = device
+
+	// Firefox only reads the download directory at startup, so one is always configured
+	f.downloadDir = f.Options.DownloadDir
+	if f.downloadDir == "" {
+		dir, err := tempDownloadDir()
+		if err != nil {
+			return err
+		}
+		f.downloadDir, f.ownsDownloadDir = dir, true
+	}
+
+	f.cmd = exec.Command("geckodriver", "--port="+driverPort(f.client.BaseURL))
 	if err := f.cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start Geckodriver: %w", err)
 	}
 
-	// Wait for Geckodriver to start
-	time.Sleep(2 * time.Second)
+	// Wait for Geckodriver to accept sessions
+	if err := f.waitDriverReady(ctx); err != nil {
+		f.Close()
+		return fmt.Errorf("Geckodriver did not become ready: %w", err)
+	}
 
 	// Create a new session
-	sessionID, err := f.createSession()
-	if err != nil {
+	if err := f.createSession(ctx, f.capabilities()); err != nil {
+		f.Close()
 		return fmt.Errorf("failed to create session: %w", err)
 	}
+	if err := f.applyOptions(ctx, f.Options); err != nil {
+		f.Close()
+		return err
+	}
+	// Contexts made by NewContext are not recorded, they would overwrite the same file
+	if f.Options.RecordHAR != "" {
+		if err := f.StartHAR(f.Options.RecordHAR); err != nil {
+			f.Close()
+			return err
+		}
+	}
 
-	f.sessionID = sessionID
-	log.Println("Firefox session created:", f.sessionID)
+	log.Println("Firefox session created:", f.session.ID)
 	return nil
 }
 
-// createSession sends a request to Geckodriver to create a new session
-func (f *Firefox) createSession() (string, error) {
-	url := "http://localhost:4444/session"
-	payload := map[string]interface{}{
-		"capabilities": map[string]interface{}{
-			"alwaysMatch": map[string]interface{}{
-				"browserName": "firefox",
-			},
-		},
+// NewContext opens a separate Firefox session with its own cookies, storage and download directory.
+// Geckodriver serves a single session, so when it refuses another one the context gets its own driver.
+func (f *Firefox) NewContext() (Browser, error) {
+	ctx := context.Background()
+	// Downloads are told apart by appearing in the directory, so contexts must not share one
+	dir, err := tempDownloadDir()
+	if err != nil {
+		return nil, err
+	}
+	isolated := &Firefox{Options: f.Options}
+	isolated.downloadDir, isolated.ownsDownloadDir, isolated.device = dir, true, f.device
+	caps := isolated.capabilities()
+	err = f.newContext(ctx, caps, &isolated.webDriverBrowser)
+	if errors.Is(err, webdriver.ErrSessionNotCreated) {
+		err = f.newDriverContext(ctx, caps, &isolated.webDriverBrowser)
 	}
-	jsonPayload, _ := json.Marshal(payload)
-
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		return "", fmt.Errorf("failed to create session: %w", err)
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create Firefox context: %w", err)
 	}
-	defer resp.Body.Close()
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode session response: %w", err)
+	isolated.DriverURL = isolated.client.BaseURL
+	if err := isolated.applyOptions(ctx, f.Options); err != nil {
+		isolated.Close()
+		return nil, err
 	}
+	return isolated, nil
+}
 
-	sessionID, ok := result["value"].(map[string]interface{})["sessionId"].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid session response format")
+// newDriverContext sets up b with another Geckodriver on a free port and a session with the capabilities on it
+func (f *Firefox) newDriverContext(ctx context.Context, caps webdriver.Capabilities, b *webDriverBrowser) error {
+	port, err := freePort()
+	if err != nil {
+		return fmt.Errorf("failed to find a free port for Geckodriver: %w", err)
 	}
+	b.name, b.wait, b.slowMo = f.name, f.wait, f.slowMo
+	b.client = webdriver.NewClient("http://localhost:" + port)
 
-	return sessionID, nil
+	b.cmd = exec.Command("geckodriver", "--port="+port)
+	if err := b.cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start Geckodriver: %w", err)
+	}
+	if err := b.waitDriverReady(ctx); err != nil {
+		b.Close()
+		return fmt.Errorf("Geckodriver did not become ready: %w", err)
+	}
+	if err := b.createSession(ctx, caps); err != nil {
+		b.Close()
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
 }
 
-// OpenURL navigates to a given URL in the currently running Firefox session
-func (f *Firefox) OpenURL(url string) error {
-	if f.sessionID == "" {
-		return fmt.Errorf("no active session, start Firefox first")
+// capabilities translates the launch options into moz:firefoxOptions
+func (f *Firefox) capabilities() webdriver.Capabilities {
+	opts := f.Options
+	firefoxOptions := map[string]interface{}{}
+
+	args := []string{}
+	if opts.IsHeadless(false) {
+		args = append(args, "-headless")
+	}
+	if opts.Devtools {
+		args = append(args, "-devtools")
+	}
+	if opts.UserDataDir != "" {
+		args = append(args, "-profile", opts.UserDataDir)
 	}
+	firefoxOptions["args"] = append(args, opts.Args...)
 
-	requestURL := fmt.Sprintf("http://localhost:4444/session/%s/url", f.sessionID)
-	payload := map[string]string{"url": url}
-	jsonPayload, _ := json.Marshal(payload)
+	if opts.Binary != "" {
+		firefoxOptions["binary"] = opts.Binary
+	}
 
-	resp, err := http.Post(requestURL, "application/json", bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return fmt.Errorf("failed to open URL: %w", err)
+	prefs := map[string]interface{}{}
+	if opts.Locale != "" {
+		prefs["intl.accept_languages"] = opts.Locale
+		prefs["intl.locale.requested"] = opts.Locale
+	}
+	if f.downloadDir != "" {
+		prefs["browser.download.folderList"] = 2
+		prefs["browser.download.dir"] = f.downloadDir
+		prefs["browser.download.useDownloadDir"] = true
+		prefs["browser.download.always_ask_before_handling_new_types"] = false
+	}
+	if f.device.UserAgent != "" {
+		prefs["general.useragent.override"] = f.device.UserAgent
+	}
+	if f.device.DeviceScaleFactor > 0 {
+		prefs["layout.css.devPixelsPerPx"] = strconv.FormatFloat(f.device.DeviceScaleFactor, 'f', -1, 64)
+	}
+	if f.device.IsMobile {
+		prefs["dom.meta-viewport.enabled"] = true
+	}
+	if f.device.HasTouch {
+		prefs["dom.w3c_touch_events.enabled"] = 1
+	}
+	environmentPrefs(opts, prefs)
+	if len(prefs) > 0 {
+		firefoxOptions["prefs"] = prefs
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to navigate to URL, status code: %d", resp.StatusCode)
+	if opts.Timezone != "" {
+		firefoxOptions["env"] = map[string]string{"TZ": opts.Timezone}
 	}
 
-	log.Println("Opened URL in Firefox:", url)
-	return nil
+	return webdriver.Capabilities{
+		BrowserName:  "firefox",
+		WebSocketURL: true,
+		Extensions:   map[string]interface{}{"moz:firefoxOptions": firefoxOptions},
+	}
+}
+
+// firefoxPermissionPrefs are the preferences granting each permission to every origin
+var firefoxPermissionPrefs = map[string]map[string]interface{}{
+	"geolocation":     {"permissions.default.geo": 1, "geo.prompt.testing": true, "geo.prompt.testing.allow": true},
+	"notifications":   {"permissions.default.desktop-notification": 1},
+	"clipboard-read":  {"dom.events.asyncClipboard.readText": true, "dom.events.testing.asyncClipboard": true},
+	"clipboard-write": {"dom.events.testing.asyncClipboard": true},
+	"camera":          {"permissions.default.camera": 1, "media.navigator.permission.disabled": true},
+	"microphone":      {"permissions.default.microphone": 1, "media.navigator.permission.disabled": true},
 }
 
-// Close shuts down the Firefox browser instance
-func (f *Firefox) Close() error {
-	if f.cmd != nil && f.cmd.Process != nil {
-		if err := f.cmd.Process.Kill(); err != nil {
-			return fmt.Errorf("failed to close Firefox: %w", err)
+// environmentPrefs adds the preferences for the geolocation, permissions and media features of the launch options
+func environmentPrefs(opts config.LaunchConfig, prefs map[string]interface{}) {
+	if location := opts.Geolocation; location != nil {
+		// The network provider is answered by a data URL instead of a location service
+		prefs["geo.provider.testing"] = true
+		prefs["geo.provider.network.url"] = fmt.Sprintf(`data:application/json,{"location":{"lat":%v,"lng":%v},"accuracy":%v}`,
+			location.Latitude, location.Longitude, location.Accuracy)
+	}
+	for _, permission := range opts.Permissions {
+		permissionPrefs, ok := firefoxPermissionPrefs[permission]
+		if !ok {
+			log.Printf("Permission %q is not supported by Firefox, ignoring it.\n", permission)
+			continue
+		}
+		for name, value := range permissionPrefs {
+			prefs[name] = value
 		}
-		log.Println("Firefox browser closed successfully.")
 	}
-	return nil
+	switch ColorScheme(opts.ColorScheme) {
+	case ColorSchemeDark:
+		prefs["layout.css.prefers-color-scheme.content-override"] = 0
+	case ColorSchemeLight:
+		prefs["layout.css.prefers-color-scheme.content-override"] = 1
+	}
+	switch ReducedMotion(opts.ReducedMotion) {
+	case ReducedMotionReduce:
+		prefs["ui.prefersReducedMotion"] = 1
+	case ReducedMotionNoPreference:
+		prefs["ui.prefersReducedMotion"] = 0
+	}
 }
@@ -0,0 +1,224 @@
+package browsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Selectors passed to Find, FindAll, WaitFor and Frame may pierce open shadow roots
+// with the ">>>" combinator: "my-widget >>> button" matches buttons inside the
+// shadow trees of my-widget elements. Each part is a plain CSS selector.
+
+// shadowCombinator separates the parts of a selector that pierces shadow roots
+const shadowCombinator = ">>>"
+
+// FrameInfo describes a frame element of the current document
+type FrameInfo struct {
+	Name string
+	ID   string
+	URL  string
+}
+
+// splitShadowSelector splits the selector into the parts searched in successive shadow trees
+func splitShadowSelector(selector string) []string {
+	parts := strings.Split(selector, shadowCombinator)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// listFrames describes the frame elements of the current document
+func listFrames(e evaluator) ([]FrameInfo, error) {
+	value, err := e.Evaluate(`() => Array.from(document.querySelectorAll("iframe, frame"), (f) => [f.name, f.id, f.src])`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list frames: %w", err)
+	}
+	items, _ := value.([]interface{})
+	frames := make([]FrameInfo, 0, len(items))
+	for _, item := range items {
+		fields, _ := item.([]interface{})
+		if len(fields) != 3 {
+			continue
+		}
+		var frame FrameInfo
+		frame.Name, _ = fields[0].(string)
+		frame.ID, _ = fields[1].(string)
+		frame.URL, _ = fields[2].(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// scope returns the frame finds and scripts run in, or the current page outside frames
+func (c *Chrome) scope() *rod.Page {
+	if len(c.frames) > 0 {
+		return c.frames[len(c.frames)-1]
+	}
+	return c.Page
+}
+
+// Frame enters the frame matched by the selector in the current frame
+func (c *Chrome) Frame(selector string) error {
+	el, err := c.Find(selector)
+	if err != nil {
+		return fmt.Errorf("failed to enter frame: %w", err)
+	}
+	frame, err := unwrapElement(el).(*chromeElement).el.Frame()
+	if err != nil {
+		return fmt.Errorf("failed to enter frame %q: %w", selector, chromeError(err))
+	}
+	c.frames = append(c.frames, frame)
+	return nil
+}
+
+// Frames describes the frames of the current frame
+func (c *Chrome) Frames() ([]FrameInfo, error) {
+	return listFrames(c)
+}
+
+// ParentFrame leaves the current frame for its parent
+func (c *Chrome) ParentFrame() error {
+	if len(c.frames) > 0 {
+		c.frames = c.frames[:len(c.frames)-1]
+	}
+	return nil
+}
+
+// MainFrame leaves all frames and returns to the top-level document
+func (c *Chrome) MainFrame() error {
+	c.frames = nil
+	return nil
+}
+
+// chromeFind locates the first element matching the selector, piercing shadow roots at each ">>>"
+func chromeFind(page *rod.Page, selector string) (*rod.Element, error) {
+	parts := splitShadowSelector(selector)
+	if len(parts) == 1 {
+		return page.Element(selector)
+	}
+	els, err := chromeFindAll(page, selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(els) == 0 {
+		return nil, &rod.ElementNotFoundError{}
+	}
+	return els[0], nil
+}
+
+// chromeFindAll locates all elements matching the selector, piercing shadow roots at each ">>>"
+func chromeFindAll(page *rod.Page, selector string) (rod.Elements, error) {
+	parts := splitShadowSelector(selector)
+	els, err := page.Elements(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts[1:] {
+		var found rod.Elements
+		for _, host := range els {
+			root, err := host.ShadowRoot()
+			if isError[*rod.NoShadowRootError](err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			inner, err := root.Elements(part)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, inner...)
+		}
+		els = found
+	}
+	return els, nil
+}
+
+// Frame enters the frame matched by the selector in the current frame
+func (b *webDriverBrowser) Frame(selector string) error {
+	el, err := b.Find(selector)
+	if err != nil {
+		return fmt.Errorf("failed to enter frame: %w", err)
+	}
+	if err := b.session.SwitchToFrame(context.Background(), unwrapElement(el).(*webDriverElement).el); err != nil {
+		return fmt.Errorf("failed to enter frame %q: %w", selector, err)
+	}
+	return nil
+}
+
+// Frames describes the frames of the current frame
+func (b *webDriverBrowser) Frames() ([]FrameInfo, error) {
+	return listFrames(b)
+}
+
+// ParentFrame leaves the current frame for its parent
+func (b *webDriverBrowser) ParentFrame() error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if err := b.session.SwitchToParentFrame(context.Background()); err != nil {
+		return fmt.Errorf("failed to leave frame: %w", err)
+	}
+	return nil
+}
+
+// MainFrame leaves all frames and returns to the top-level document
+func (b *webDriverBrowser) MainFrame() error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if err := b.session.SwitchToFrame(context.Background(), nil); err != nil {
+		return fmt.Errorf("failed to leave frames: %w", err)
+	}
+	return nil
+}
+
+// webDriverFind locates the first element matching the selector, piercing shadow roots at each ">>>"
+func webDriverFind(ctx context.Context, session *webdriver.Session, selector string) (*webdriver.Element, error) {
+	parts := splitShadowSelector(selector)
+	if len(parts) == 1 {
+		return session.FindElement(ctx, webdriver.ByCSSSelector, selector)
+	}
+	els, err := webDriverFindAll(ctx, session, selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(els) == 0 {
+		return nil, &webdriver.Error{Code: webdriver.CodeNoSuchElement, Message: fmt.Sprintf("no element matches %q", selector)}
+	}
+	return els[0], nil
+}
+
+// webDriverFindAll locates all elements matching the selector, piercing shadow roots at each ">>>"
+func webDriverFindAll(ctx context.Context, session *webdriver.Session, selector string) ([]*webdriver.Element, error) {
+	parts := splitShadowSelector(selector)
+	els, err := session.FindElements(ctx, webdriver.ByCSSSelector, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts[1:] {
+		var found []*webdriver.Element
+		for _, host := range els {
+			root, err := host.ShadowRoot(ctx)
+			if errors.Is(err, webdriver.ErrNoSuchShadowRoot) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			inner, err := root.FindElements(ctx, webdriver.ByCSSSelector, part)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, inner...)
+		}
+		els = found
+	}
+	return els, nil
+}
@@ -0,0 +1,581 @@
+package browsers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// HAR is an HTTP Archive 1.2 log of the network traffic of a browser
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+// HARLog holds the recorded requests in the order they were sent
+type HARLog struct {
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Entries []*HAREntry `json:"entries"`
+}
+
+// HARCreator names the tool that wrote the HAR
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HAREntry is a request and the response it got, its times are in milliseconds
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+// HARRequest is a recorded request, sizes are -1 when unknown
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+// HARPostData is the body of a recorded request
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// HARResponse is a recorded response, sizes are -1 when unknown.
+// A failed request has status 0 and its network error in Error.
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Error       string         `json:"_error,omitempty"`
+}
+
+// HARContent is a response body, binary bodies are base64 encoded with Encoding "base64"
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARNameValue is a header, cookie or query parameter
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARTimings splits the time of an entry into its phases
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// ReadHAR loads a HAR file, such as one written by StopHAR
+func ReadHAR(path string) (*HAR, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR: %w", err)
+	}
+	var har HAR
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR %s: %w", path, err)
+	}
+	return &har, nil
+}
+
+// WriteFile saves the HAR as JSON, readable only by the current user as headers may hold credentials
+func (h *HAR) WriteFile(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write HAR: %w", err)
+	}
+	return nil
+}
+
+// body returns the decoded text of the content
+func (c HARContent) body() string {
+	if c.Encoding == "base64" {
+		data, err := base64.StdEncoding.DecodeString(c.Text)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return c.Text
+}
+
+// harRequest describes a request for the HAR, its URL without the fragment, which is never sent
+func harRequest(method, rawURL string, headers map[string]string, body string) HARRequest {
+	if i := strings.IndexByte(rawURL, '#'); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	req := HARRequest{
+		Method:      method,
+		URL:         rawURL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []HARNameValue{},
+		Headers:     harNameValues(headers),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		for name, values := range u.Query() {
+			for _, value := range values {
+				req.QueryString = append(req.QueryString, HARNameValue{Name: name, Value: value})
+			}
+		}
+	}
+	if body != "" {
+		req.PostData = &HARPostData{MimeType: headerValue(headers, "Content-Type"), Text: body}
+	}
+	return req
+}
+
+// harResponse describes a response for the HAR, its content is added once the body is received
+func harResponse(status int, statusText, protocol string, headers map[string]string, mimeType string) HARResponse {
+	return HARResponse{
+		Status:      status,
+		StatusText:  statusText,
+		HTTPVersion: harHTTPVersion(protocol),
+		Cookies:     []HARNameValue{},
+		Headers:     harNameValues(headers),
+		Content:     HARContent{MimeType: mimeType},
+		RedirectURL: headerValue(headers, "Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+// harHTTPVersion translates protocol names such as "h2" into HTTP versions
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return "HTTP/1.1"
+	case "h2":
+		return "HTTP/2"
+	case "h3":
+		return "HTTP/3"
+	}
+	return strings.ToUpper(protocol)
+}
+
+// harNameValues lists the headers sorted by name, so recordings of the same traffic compare equal
+func harNameValues(headers map[string]string) []HARNameValue {
+	list := make([]HARNameValue, 0, len(headers))
+	for name, value := range headers {
+		list = append(list, HARNameValue{Name: name, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// headerValue looks the header up case-insensitively
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// newHAR returns a HAR log of the entries
+func newHAR(entries []*HAREntry) *HAR {
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "go-owl", Version: "1.0"},
+		Entries: entries,
+	}}
+}
+
+// harRecorder collects the entries of a HAR while recording.
+// Network events arrive on event goroutines, so access is guarded.
+type harRecorder struct {
+	mu      sync.Mutex
+	path    string // empty while not recording
+	entries []*HAREntry
+	pending map[string]*harPending
+}
+
+// harPending is an entry whose response is not complete yet, with its times on the event clock
+type harPending struct {
+	entry    *HAREntry
+	sent     time.Duration
+	received time.Duration
+}
+
+func (r *harRecorder) start(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.path != "" {
+		return fmt.Errorf("already recording a HAR into %s", r.path)
+	}
+	r.path, r.entries, r.pending = path, []*HAREntry{}, map[string]*harPending{}
+	return nil
+}
+
+// request adds an entry for a request sent at the time on the event clock
+func (r *harRecorder) request(id string, started time.Time, at time.Duration, req HARRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.path == "" {
+		return
+	}
+	entry := &HAREntry{
+		StartedDateTime: started,
+		Request:         req,
+		Response:        harResponse(0, "", "", nil, ""),
+	}
+	r.entries = append(r.entries, entry)
+	r.pending[id] = &harPending{entry: entry, sent: at, received: at}
+}
+
+// response records the status and headers of the request's response
+func (r *harRecorder) response(id string, at time.Duration, res HARResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.pending[id]
+	if p == nil {
+		return
+	}
+	p.entry.Response, p.received = res, at
+	p.entry.Timings.Wait = milliseconds(at - p.sent)
+}
+
+// finish completes the entry once the whole body is received
+func (r *harRecorder) finish(id string, at time.Duration, content HARContent, bodySize int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.pending[id]
+	if p == nil {
+		return
+	}
+	delete(r.pending, id)
+
+	res := &p.entry.Response
+	res.Content.Text, res.Content.Encoding = content.Text, content.Encoding
+	res.Content.Size = int64(len(content.body()))
+	res.BodySize = bodySize
+	p.entry.Timings.Receive = milliseconds(at - p.received)
+	p.entry.Time = milliseconds(at - p.sent)
+}
+
+// fail completes the entry of a request that got no response
+func (r *harRecorder) fail(id string, at time.Duration, errorText string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.pending[id]
+	if p == nil {
+		return
+	}
+	delete(r.pending, id)
+	p.entry.Response.Error = errorText
+	p.entry.Time = milliseconds(at - p.sent)
+}
+
+// snapshot returns copies of the entries recorded so far, false when not recording
+func (r *harRecorder) snapshot() ([]*HAREntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.path == "" {
+		return nil, false
+	}
+	entries := make([]*HAREntry, len(r.entries))
+	for i, entry := range r.entries {
+		copied := *entry
+		entries[i] = &copied
+	}
+	return entries, true
+}
+
+// stop ends the recording and returns the HAR with the path it goes to, an empty path when not recording
+func (r *harRecorder) stop() (*HAR, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	har := newHAR(r.entries)
+	path := r.path
+	r.path, r.entries, r.pending = "", nil, nil
+	return har, path
+}
+
+// harReplay answers requests with the responses recorded for them
+type harReplay struct {
+	mu      sync.Mutex
+	entries map[string][]*HAREntry // by method and URL
+	served  map[string]int
+}
+
+func newHARReplay(har *HAR) *harReplay {
+	replay := &harReplay{entries: map[string][]*HAREntry{}, served: map[string]int{}}
+	for _, entry := range har.Log.Entries {
+		key := harReplayKey(entry.Request.Method, entry.Request.URL)
+		replay.entries[key] = append(replay.entries[key], entry)
+	}
+	return replay
+}
+
+// harReplayKey is the method and URL a request is looked up by, without any fragment,
+// which intercepted requests never have but HARs from other tools may
+func harReplayKey(method, url string) string {
+	if i := strings.IndexByte(url, '#'); i >= 0 {
+		url = url[:i]
+	}
+	return method + " " + url
+}
+
+// handle fulfills the request with the next response recorded for it, repeating the last one,
+// and aborts the requests the HAR does not hold so nothing reaches the network
+func (h *harReplay) handle(route *Route) {
+	key := harReplayKey(route.Request.Method, route.Request.URL)
+	h.mu.Lock()
+	entries := h.entries[key]
+	if len(entries) == 0 {
+		h.mu.Unlock()
+		route.Abort()
+		return
+	}
+	entry := entries[min(h.served[key], len(entries)-1)]
+	h.served[key]++
+	h.mu.Unlock()
+
+	if entry.Response.Status == 0 {
+		route.Abort()
+		return
+	}
+	headers := make(map[string]string, len(entry.Response.Headers))
+	for _, header := range entry.Response.Headers {
+		// The recorded body is decoded, so its original encoding and length no longer apply
+		switch strings.ToLower(header.Name) {
+		case "content-encoding", "content-length", "transfer-encoding":
+			continue
+		}
+		headers[header.Name] = header.Value
+	}
+	route.Fulfill(Response{Status: entry.Response.Status, Headers: headers, Body: entry.Response.Content.body()})
+}
+
+// routeFromHAR adds a route for every request that replays the HAR file
+func routeFromHAR(route func(RouteMatch, RouteHandler) error, path string) error {
+	har, err := ReadHAR(path)
+	if err != nil {
+		return err
+	}
+	if err := route(RouteMatch{}, newHARReplay(har).handle); err != nil {
+		return fmt.Errorf("failed to replay HAR: %w", err)
+	}
+	return nil
+}
+
+// StartHAR records the network traffic of every page in the browser context into a HAR,
+// written to path by StopHAR or Close
+func (c *Chrome) StartHAR(path string) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	if err := c.har.start(path); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopHAR = cancel
+	var mu sync.Mutex
+	recorded := map[proto.TargetTargetID]bool{}
+	err := c.watchPages(ctx, func(page *rod.Page) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !recorded[page.TargetID] {
+			recorded[page.TargetID] = true
+			c.recordPage(ctx, page)
+		}
+		return nil
+	})
+	if err != nil {
+		cancel()
+		c.stopHAR = nil
+		c.har.stop()
+		return fmt.Errorf("failed to record HAR: %w", err)
+	}
+	return nil
+}
+
+// StopHAR stops recording and writes the HAR, it does nothing when not recording
+func (c *Chrome) StopHAR() error {
+	if c.stopHAR != nil {
+		c.stopHAR()
+		c.stopHAR = nil
+	}
+	har, path := c.har.stop()
+	if path == "" {
+		return nil
+	}
+	return har.WriteFile(path)
+}
+
+// harEntries returns the entries of the HAR being recorded, false when not recording
+func (c *Chrome) harEntries() ([]*HAREntry, bool) {
+	return c.har.snapshot()
+}
+
+// RouteFromHAR answers the requests recorded in the HAR file with their recorded responses and aborts all others.
+// Routes added later take precedence, Unroute(RouteMatch{}) ends the replay.
+func (c *Chrome) RouteFromHAR(path string) error {
+	return routeFromHAR(c.Route, path)
+}
+
+// recordPage adds the network traffic of the page to the HAR until ctx is done
+func (c *Chrome) recordPage(ctx context.Context, page *rod.Page) {
+	// Request IDs are only unique within a page
+	key := func(id proto.NetworkRequestID) string {
+		return string(page.TargetID) + "/" + string(id)
+	}
+	wait := page.Context(ctx).EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		at := e.Timestamp.Duration()
+		if e.RedirectResponse != nil {
+			// A redirect reuses the request ID for the request it leads to
+			c.har.response(key(e.RequestID), at, chromeHARResponse(e.RedirectResponse))
+			c.har.finish(key(e.RequestID), at, HARContent{}, 0)
+		}
+		req := harRequest(e.Request.Method, e.Request.URL, chromeHeaders(e.Request.Headers), e.Request.PostData)
+		c.har.request(key(e.RequestID), e.WallTime.Time(), at, req)
+	}, func(e *proto.NetworkResponseReceived) {
+		c.har.response(key(e.RequestID), e.Timestamp.Duration(), chromeHARResponse(e.Response))
+	}, func(e *proto.NetworkLoadingFinished) {
+		var content HARContent
+		// Fails for bodies Chrome did not keep, such as those of page navigations away
+		if body, err := (proto.NetworkGetResponseBody{RequestID: e.RequestID}).Call(page); err == nil {
+			content.Text = body.Body
+			if body.Base64Encoded {
+				content.Encoding = "base64"
+			}
+		}
+		c.har.finish(key(e.RequestID), e.Timestamp.Duration(), content, int64(e.EncodedDataLength))
+	}, func(e *proto.NetworkLoadingFailed) {
+		c.har.fail(key(e.RequestID), e.Timestamp.Duration(), e.ErrorText)
+	})
+	go wait()
+}
+
+func chromeHARResponse(res *proto.NetworkResponse) HARResponse {
+	return harResponse(res.Status, res.StatusText, res.Protocol, chromeHeaders(res.Headers), res.MIMEType)
+}
+
+// chromeHeaders flattens CDP headers, Chrome joins repeated headers with newlines
+func chromeHeaders(headers proto.NetworkHeaders) map[string]string {
+	flat := make(map[string]string, len(headers))
+	for name, value := range headers {
+		flat[name] = value.String()
+	}
+	return flat
+}
+
+// StartHAR records the network traffic of the session into a HAR, written to path by StopHAR or Close.
+// WebDriver BiDi does not expose bodies, so Firefox records responses without content.
+// Safari offers no BiDi and reports ErrUnsupported.
+func (b *webDriverBrowser) StartHAR(path string) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if b.bidi == nil {
+		return fmt.Errorf("HAR recording needs WebDriver BiDi, which %s does not offer: %w", b.name, ErrUnsupported)
+	}
+	if err := b.har.start(path); err != nil {
+		return err
+	}
+
+	if !b.harEvents {
+		b.bidi.OnBeforeRequestSent(func(e webdriver.BeforeRequestSent) {
+			req := harRequest(e.Request.Method, e.Request.URL, bidiHeaderMap(e.Request.Headers), "")
+			b.har.request(e.Request.Request, time.UnixMilli(e.Timestamp), bidiTime(e.Timestamp), req)
+		})
+		b.bidi.OnResponseCompleted(func(e webdriver.ResponseCompleted) {
+			res := e.Response
+			at := bidiTime(e.Timestamp)
+			b.har.response(e.Request.Request, at, harResponse(res.Status, res.StatusText, res.Protocol, bidiHeaderMap(res.Headers), res.MimeType))
+			bodySize := int64(-1)
+			if res.BodySize != nil {
+				bodySize = *res.BodySize
+			}
+			b.har.finish(e.Request.Request, at, HARContent{}, bodySize)
+		})
+		b.bidi.OnFetchError(func(e webdriver.FetchError) {
+			b.har.fail(e.Request.Request, bidiTime(e.Timestamp), e.ErrorText)
+		})
+		b.harEvents = true
+	}
+	err := b.bidi.Subscribe(context.Background(), "network.beforeRequestSent", "network.responseCompleted", "network.fetchError")
+	if err != nil {
+		b.har.stop()
+		return fmt.Errorf("failed to record HAR: %w", err)
+	}
+	return nil
+}
+
+// StopHAR stops recording and writes the HAR, it does nothing when not recording
+func (b *webDriverBrowser) StopHAR() error {
+	har, path := b.har.stop()
+	if path == "" {
+		return nil
+	}
+	return har.WriteFile(path)
+}
+
+// harEntries returns the entries of the HAR being recorded, false when not recording
+func (b *webDriverBrowser) harEntries() ([]*HAREntry, bool) {
+	return b.har.snapshot()
+}
+
+// RouteFromHAR answers the requests recorded in the HAR file with their recorded responses and aborts all others.
+// Routes added later take precedence, Unroute(RouteMatch{}) ends the replay.
+func (b *webDriverBrowser) RouteFromHAR(path string) error {
+	return routeFromHAR(b.Route, path)
+}
+
+// bidiTime places a BiDi timestamp, in milliseconds since the epoch, on the event clock
+func bidiTime(timestamp int64) time.Duration {
+	return time.Duration(timestamp) * time.Millisecond
+}
+
+func bidiHeaderMap(headers []webdriver.Header) map[string]string {
+	flat := make(map[string]string, len(headers))
+	for _, header := range headers {
+		flat[header.Name] = header.Value.String()
+	}
+	return flat
+}
@@ -0,0 +1,142 @@
+package browsers
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHARRequest(t *testing.T) {
+	req := harRequest("POST", "https://example.com/search?q=owl&tag=a&tag=b#results",
+		map[string]string{"Content-Type": "application/json"}, `{"page":2}`)
+	if req.URL != "https://example.com/search?q=owl&tag=a&tag=b" {
+		t.Errorf("URL = %q, want it without the fragment", req.URL)
+	}
+	query := map[string][]string{}
+	for _, param := range req.QueryString {
+		query[param.Name] = append(query[param.Name], param.Value)
+	}
+	if want := map[string][]string{"q": {"owl"}, "tag": {"a", "b"}}; !reflect.DeepEqual(query, want) {
+		t.Errorf("QueryString = %v, want %v", query, want)
+	}
+	if req.PostData == nil || req.PostData.MimeType != "application/json" || req.PostData.Text != `{"page":2}` {
+		t.Errorf("PostData = %+v, want the JSON body", req.PostData)
+	}
+	if req.BodySize != 10 {
+		t.Errorf("BodySize = %d, want 10", req.BodySize)
+	}
+}
+
+func TestHARReplay(t *testing.T) {
+	entry := func(method, url string, status int, body string) *HAREntry {
+		return &HAREntry{
+			Request: HARRequest{Method: method, URL: url},
+			Response: HARResponse{
+				Status: status,
+				Headers: []HARNameValue{
+					{Name: "Content-Type", Value: "text/plain"},
+					{Name: "Content-Encoding", Value: "gzip"},
+					{Name: "Content-Length", Value: "999"},
+				},
+				Content: HARContent{Text: body},
+			},
+		}
+	}
+	encoded := entry("GET", "https://example.com/logo.png", 200, base64.StdEncoding.EncodeToString([]byte("png")))
+	encoded.Response.Content.Encoding = "base64"
+	har := newHAR([]*HAREntry{
+		entry("GET", "https://example.com/poll", 200, "first"),
+		entry("GET", "https://example.com/poll", 200, "second"),
+		entry("GET", "https://example.com/docs#intro", 200, "docs"),
+		entry("POST", "https://example.com/login", 0, ""),
+		encoded,
+	})
+	replay := newHARReplay(har)
+
+	tests := []struct {
+		name   string
+		req    Request
+		action routeAction
+		body   string
+	}{
+		{"first response", Request{Method: "GET", URL: "https://example.com/poll"}, routeFulfill, "first"},
+		{"next response", Request{Method: "GET", URL: "https://example.com/poll"}, routeFulfill, "second"},
+		{"last response repeats", Request{Method: "GET", URL: "https://example.com/poll"}, routeFulfill, "second"},
+		{"recorded with fragment", Request{Method: "GET", URL: "https://example.com/docs"}, routeFulfill, "docs"},
+		{"requested with fragment", Request{Method: "GET", URL: "https://example.com/docs#usage"}, routeFulfill, "docs"},
+		{"decoded body", Request{Method: "GET", URL: "https://example.com/logo.png"}, routeFulfill, "png"},
+		{"recorded failure", Request{Method: "POST", URL: "https://example.com/login"}, routeAbort, ""},
+		{"other method", Request{Method: "POST", URL: "https://example.com/poll"}, routeAbort, ""},
+		{"not recorded", Request{Method: "GET", URL: "https://example.com/other"}, routeAbort, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := &Route{Request: tt.req}
+			replay.handle(route)
+			if route.action != tt.action {
+				t.Fatalf("action = %d, want %d", route.action, tt.action)
+			}
+			if tt.action != routeFulfill {
+				return
+			}
+			if route.response.Body != tt.body {
+				t.Errorf("body = %q, want %q", route.response.Body, tt.body)
+			}
+			want := map[string]string{"Content-Type": "text/plain"}
+			if !reflect.DeepEqual(route.response.Headers, want) {
+				t.Errorf("headers = %v, want %v without the encoding and length", route.response.Headers, want)
+			}
+		})
+	}
+}
+
+func TestHARRecorder(t *testing.T) {
+	var r harRecorder
+	r.request("ignored", time.Now(), 0, harRequest("GET", "https://example.com/", nil, ""))
+	if entries, recording := r.snapshot(); recording || entries != nil {
+		t.Fatal("recorder records before start")
+	}
+
+	path := filepath.Join(t.TempDir(), "network.har")
+	if err := r.start(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.start(path); err == nil {
+		t.Error("second start succeeded, want an error")
+	}
+	started := time.Now()
+	r.request("1", started, time.Second, harRequest("GET", "https://example.com/", nil, ""))
+	r.response("1", 1300*time.Millisecond, harResponse(200, "OK", "h2", nil, "text/html"))
+	r.finish("1", 1500*time.Millisecond, HARContent{Text: "<p>hi</p>"}, 9)
+	r.request("2", started, 2*time.Second, harRequest("GET", "https://example.com/gone", nil, ""))
+	r.fail("2", 2100*time.Millisecond, "net::ERR_FAILED")
+
+	entries, recording := r.snapshot()
+	if !recording || len(entries) != 2 {
+		t.Fatalf("snapshot() = %d entries, %v, want 2 entries while recording", len(entries), recording)
+	}
+	entries[0].Response.Status = 500
+	if first := r.entries[0]; first.Response.Status != 200 || first.Time != 500 || first.Timings.Wait != 300 || first.Timings.Receive != 200 {
+		t.Errorf("first entry = %+v, want status 200 taking 500ms", first)
+	}
+	if second := r.entries[1]; second.Response.Error != "net::ERR_FAILED" {
+		t.Errorf("second entry error = %q, want net::ERR_FAILED", second.Response.Error)
+	}
+
+	har, stopped := r.stop()
+	if stopped != path || len(har.Log.Entries) != 2 || har.Log.Version != "1.2" {
+		t.Errorf("stop() = %q with %d entries, want %q with 2", stopped, len(har.Log.Entries), path)
+	}
+	if err := har.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadHAR(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read.Log.Entries) != 2 || read.Log.Entries[0].Response.Content.Text != "<p>hi</p>" {
+		t.Errorf("ReadHAR() = %+v, want the written entries", read.Log)
+	}
+}
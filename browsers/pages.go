@@ -0,0 +1,387 @@
+package browsers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// PageID identifies a tab or window: the target ID in Chrome, the window handle in WebDriver
+type PageID string
+
+// WindowRect is the position and size of a browser window in pixels
+type WindowRect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// waitNewPage runs the action and waits until a page that did not exist before it shows up
+func waitNewPage(ctx context.Context, opts WaitOptions, pages func() ([]PageID, error), action func() error) (PageID, error) {
+	before, err := pages()
+	if err != nil {
+		return "", err
+	}
+	known := make(map[PageID]bool, len(before))
+	for _, page := range before {
+		known[page] = true
+	}
+
+	if err := action(); err != nil {
+		return "", err
+	}
+
+	var opened PageID
+	err = poll(ctx, opts, func() (bool, error) {
+		current, err := pages()
+		if err != nil {
+			return false, err
+		}
+		for _, page := range current {
+			if !known[page] {
+				opened = page
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("waiting for a new page: %w", err)
+	}
+	return opened, nil
+}
+
+// NewPage opens a new tab and makes it the current page
+func (c *Chrome) NewPage() (PageID, error) {
+	if c.Browser == nil {
+		return "", fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	page, err := c.Browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return "", fmt.Errorf("failed to open page: %w", chromeError(err))
+	}
+	// Input held on the previous page must not carry over to this one
+	c.ReleaseActions()
+	c.usePage(page)
+	return PageID(page.TargetID), nil
+}
+
+// Pages returns the open tabs and windows of the browser context
+func (c *Chrome) Pages() ([]PageID, error) {
+	if c.Browser == nil {
+		return nil, fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	targets, err := proto.TargetGetTargets{}.Call(c.Browser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", chromeError(err))
+	}
+
+	// The default context has no ID of its own, it holds every target outside the created contexts
+	others := map[proto.BrowserBrowserContextID]bool{}
+	if c.Browser.BrowserContextID == "" {
+		contexts, err := proto.TargetGetBrowserContexts{}.Call(c.Browser)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pages: %w", chromeError(err))
+		}
+		for _, id := range contexts.BrowserContextIDs {
+			others[id] = true
+		}
+	}
+
+	pages := []PageID{}
+	for _, target := range targets.TargetInfos {
+		if target.Type != proto.TargetTargetInfoTypePage {
+			continue
+		}
+		if others[target.BrowserContextID] || (c.Browser.BrowserContextID != "" && target.BrowserContextID != c.Browser.BrowserContextID) {
+			continue
+		}
+		pages = append(pages, PageID(target.TargetID))
+	}
+	return pages, nil
+}
+
+// watchPages calls attach for every page of the browser context, now and whenever one opens, until ctx is done.
+// Pages opening later are attached once Chrome reports them, so their very first requests may go unseen.
+// attach must tolerate being called twice for a page.
+func (c *Chrome) watchPages(ctx context.Context, attach func(*rod.Page) error) error {
+	wait := c.Browser.Context(ctx).EachEvent(func(e *proto.TargetTargetCreated) {
+		if e.TargetInfo.Type != proto.TargetTargetInfoTypePage {
+			return
+		}
+		// Pages lists only the pages of this context
+		pages, err := c.Pages()
+		if err != nil {
+			return
+		}
+		for _, id := range pages {
+			if id != PageID(e.TargetInfo.TargetID) {
+				continue
+			}
+			if page, err := c.Browser.PageFromTarget(e.TargetInfo.TargetID); err == nil {
+				attach(page)
+			}
+		}
+	})
+	go wait()
+
+	pages, err := c.Pages()
+	if err != nil {
+		return err
+	}
+	for _, id := range pages {
+		page, err := c.Browser.PageFromTarget(proto.TargetTargetID(id))
+		if err != nil {
+			return chromeError(err)
+		}
+		if err := attach(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usePage makes the page current, leaving any frames
+func (c *Chrome) usePage(page *rod.Page) {
+	c.Page, c.frames = page, nil
+}
+
+// CurrentPage returns the page the browser currently acts on
+func (c *Chrome) CurrentPage() (PageID, error) {
+	if c.Page == nil {
+		return "", fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	return PageID(c.Page.TargetID), nil
+}
+
+// SwitchTo makes the page current and brings it to the front
+func (c *Chrome) SwitchTo(page PageID) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	p, err := c.Browser.PageFromTarget(proto.TargetTargetID(page))
+	if err != nil {
+		return fmt.Errorf("failed to switch to page %s: %w", page, chromeError(err))
+	}
+	if _, err := p.Activate(); err != nil {
+		return fmt.Errorf("failed to switch to page %s: %w", page, chromeError(err))
+	}
+	// Input held on the previous page must not carry over to this one
+	c.ReleaseActions()
+	c.usePage(p)
+	return nil
+}
+
+// ClosePage closes the current page and switches to one of the remaining pages
+func (c *Chrome) ClosePage() error {
+	if c.Page == nil {
+		return fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	c.ReleaseActions()
+	if err := c.Page.Close(); err != nil {
+		return fmt.Errorf("failed to close page: %w", chromeError(err))
+	}
+	c.Page, c.frames = nil, nil
+
+	pages, err := c.Pages()
+	if err != nil || len(pages) == 0 {
+		return err
+	}
+	return c.SwitchTo(pages[0])
+}
+
+// WaitForPage runs the action and waits for the popup, tab or window it opens.
+// The current page stays unchanged, use SwitchTo to act on the new one.
+func (c *Chrome) WaitForPage(action func() error) (PageID, error) {
+	return waitNewPage(context.Background(), c.wait, c.Pages, action)
+}
+
+// WindowRect returns the position and size of the current page's window
+func (c *Chrome) WindowRect() (WindowRect, error) {
+	if c.Page == nil {
+		return WindowRect{}, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	bounds, err := c.Page.GetWindow()
+	if err != nil {
+		return WindowRect{}, fmt.Errorf("failed to get window rect: %w", chromeError(err))
+	}
+
+	return WindowRect{X: intValue(bounds.Left), Y: intValue(bounds.Top), Width: intValue(bounds.Width), Height: intValue(bounds.Height)}, nil
+}
+
+// intValue dereferences an optional CDP integer
+func intValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// SetWindowRect restores the current page's window and moves and resizes it
+func (c *Chrome) SetWindowRect(rect WindowRect) error {
+	return c.setWindow(&proto.BrowserBounds{
+		Left:   &rect.X,
+		Top:    &rect.Y,
+		Width:  &rect.Width,
+		Height: &rect.Height,
+	})
+}
+
+// MaximizeWindow maximizes the current page's window
+func (c *Chrome) MaximizeWindow() error {
+	return c.setWindow(&proto.BrowserBounds{WindowState: proto.BrowserWindowStateMaximized})
+}
+
+// MinimizeWindow minimizes the current page's window
+func (c *Chrome) MinimizeWindow() error {
+	return c.setWindow(&proto.BrowserBounds{WindowState: proto.BrowserWindowStateMinimized})
+}
+
+func (c *Chrome) setWindow(bounds *proto.BrowserBounds) error {
+	if c.Page == nil {
+		return fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	if bounds.Width != nil {
+		// Chrome only changes the geometry of windows in the normal state
+		if err := c.Page.SetWindow(&proto.BrowserBounds{WindowState: proto.BrowserWindowStateNormal}); err != nil {
+			return fmt.Errorf("failed to set window: %w", chromeError(err))
+		}
+	}
+	if err := c.Page.SetWindow(bounds); err != nil {
+		return fmt.Errorf("failed to set window: %w", chromeError(err))
+	}
+	return nil
+}
+
+// NewPage opens a new tab and makes it the current page
+func (b *webDriverBrowser) NewPage() (PageID, error) {
+	if err := b.checkSession(); err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	handle, err := b.session.NewWindow(ctx, webdriver.WindowTypeTab)
+	if err != nil {
+		return "", fmt.Errorf("failed to open page: %w", err)
+	}
+	if err := b.session.SwitchToWindow(ctx, handle); err != nil {
+		return "", fmt.Errorf("failed to switch to page %s: %w", handle, err)
+	}
+	return PageID(handle), nil
+}
+
+// Pages returns the open tabs and windows of the session
+func (b *webDriverBrowser) Pages() ([]PageID, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	handles, err := b.session.WindowHandles(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pages: %w", err)
+	}
+	pages := make([]PageID, len(handles))
+	for i, handle := range handles {
+		pages[i] = PageID(handle)
+	}
+	return pages, nil
+}
+
+// CurrentPage returns the page the browser currently acts on
+func (b *webDriverBrowser) CurrentPage() (PageID, error) {
+	if err := b.checkSession(); err != nil {
+		return "", err
+	}
+	handle, err := b.session.WindowHandle(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to get current page: %w", err)
+	}
+	return PageID(handle), nil
+}
+
+// SwitchTo makes the page current
+func (b *webDriverBrowser) SwitchTo(page PageID) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if err := b.session.SwitchToWindow(context.Background(), string(page)); err != nil {
+		return fmt.Errorf("failed to switch to page %s: %w", page, err)
+	}
+	return nil
+}
+
+// ClosePage closes the current page and switches to one of the remaining pages
+func (b *webDriverBrowser) ClosePage() error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	b.session.ReleaseActions(ctx)
+	remaining, err := b.session.CloseWindow(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to close page: %w", err)
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	return b.SwitchTo(PageID(remaining[0]))
+}
+
+// WaitForPage runs the action and waits for the popup, tab or window it opens.
+// The current page stays unchanged, use SwitchTo to act on the new one.
+func (b *webDriverBrowser) WaitForPage(action func() error) (PageID, error) {
+	return waitNewPage(context.Background(), b.wait, b.Pages, action)
+}
+
+// WindowRect returns the position and size of the current window
+func (b *webDriverBrowser) WindowRect() (WindowRect, error) {
+	if err := b.checkSession(); err != nil {
+		return WindowRect{}, err
+	}
+	rect, err := b.session.WindowRect(context.Background())
+	if err != nil {
+		return WindowRect{}, fmt.Errorf("failed to get window rect: %w", err)
+	}
+	return WindowRect{X: int(rect.X), Y: int(rect.Y), Width: int(rect.Width), Height: int(rect.Height)}, nil
+}
+
+// SetWindowRect restores the current window and moves and resizes it
+func (b *webDriverBrowser) SetWindowRect(rect WindowRect) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	_, err := b.session.SetWindowRect(context.Background(), webdriver.Rect{
+		X:      float64(rect.X),
+		Y:      float64(rect.Y),
+		Width:  float64(rect.Width),
+		Height: float64(rect.Height),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set window: %w", err)
+	}
+	return nil
+}
+
+// MaximizeWindow maximizes the current window
+func (b *webDriverBrowser) MaximizeWindow() error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if err := b.session.MaximizeWindow(context.Background()); err != nil {
+		return fmt.Errorf("failed to set window: %w", err)
+	}
+	return nil
+}
+
+// MinimizeWindow minimizes the current window
+func (b *webDriverBrowser) MinimizeWindow() error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if err := b.session.MinimizeWindow(context.Background()); err != nil {
+		return fmt.Errorf("failed to set window: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package browsers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// PaperSize is a paper size in inches
+type PaperSize struct {
+	Width  float64
+	Height float64
+}
+
+// Common paper sizes
+var (
+	PaperLetter = PaperSize{8.5, 11}
+	PaperLegal  = PaperSize{8.5, 14}
+	PaperA3     = PaperSize{11.69, 16.54}
+	PaperA4     = PaperSize{8.27, 11.69}
+	PaperA5     = PaperSize{5.83, 8.27}
+)
+
+// Margins are page margins in inches
+type Margins struct {
+	Top    float64
+	Bottom float64
+	Left   float64
+	Right  float64
+}
+
+// PDFOptions control how a page is printed. The zero value prints every page
+// in portrait on letter paper with the browser's default margins.
+type PDFOptions struct {
+	Paper PaperSize
+	// Margins are the page margins, nil keeps the browser's default
+	Margins   *Margins
+	Scale     float64
+	Landscape bool
+	// PrintBackground includes background colors and images
+	PrintBackground bool
+	// HeaderTemplate and FooterTemplate are HTML printed on every page. Elements with the classes
+	// date, title, url, pageNumber and totalPages get those values. Setting either shows both.
+	HeaderTemplate string
+	FooterTemplate string
+	// PageRanges selects pages, such as "1-5, 8", empty prints all of them
+	PageRanges string
+}
+
+const centimetersPerInch = 2.54
+
+// PDF prints the current page as PDF
+func (c *Chrome) PDF(opts PDFOptions) ([]byte, error) {
+	if c.Page == nil {
+		return nil, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	req := &proto.PagePrintToPDF{
+		Landscape:           opts.Landscape,
+		DisplayHeaderFooter: opts.HeaderTemplate != "" || opts.FooterTemplate != "",
+		PrintBackground:     opts.PrintBackground,
+		PageRanges:          opts.PageRanges,
+		HeaderTemplate:      opts.HeaderTemplate,
+		FooterTemplate:      opts.FooterTemplate,
+	}
+	if opts.Scale > 0 {
+		req.Scale = &opts.Scale
+	}
+	if opts.Paper.Width > 0 && opts.Paper.Height > 0 {
+		req.PaperWidth, req.PaperHeight = &opts.Paper.Width, &opts.Paper.Height
+	}
+	if margins := opts.Margins; margins != nil {
+		req.MarginTop, req.MarginBottom = &margins.Top, &margins.Bottom
+		req.MarginLeft, req.MarginRight = &margins.Left, &margins.Right
+	}
+
+	stream, err := c.Page.PDF(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to print PDF: %w", chromeError(err))
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", chromeError(err))
+	}
+	return data, nil
+}
+
+// PDF prints the current page as PDF through the WebDriver print command.
+// The command has no header and footer, so templates are refused.
+func (b *webDriverBrowser) PDF(opts PDFOptions) ([]byte, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	if opts.HeaderTemplate != "" || opts.FooterTemplate != "" {
+		return nil, fmt.Errorf("%s prints no header and footer templates: %w", b.name, ErrUnsupported)
+	}
+
+	params := webdriver.PrintOptions{
+		Scale:      opts.Scale,
+		Background: opts.PrintBackground,
+		PageRanges: printPageRanges(opts.PageRanges),
+	}
+	if opts.Landscape {
+		params.Orientation = "landscape"
+	}
+	if opts.Paper.Width > 0 && opts.Paper.Height > 0 {
+		params.Page = &webdriver.PrintPage{
+			Width:  opts.Paper.Width * centimetersPerInch,
+			Height: opts.Paper.Height * centimetersPerInch,
+		}
+	}
+	if margins := opts.Margins; margins != nil {
+		params.Margin = &webdriver.PrintMargin{
+			Top:    margins.Top * centimetersPerInch,
+			Bottom: margins.Bottom * centimetersPerInch,
+			Left:   margins.Left * centimetersPerInch,
+			Right:  margins.Right * centimetersPerInch,
+		}
+	}
+
+	data, err := b.session.Print(context.Background(), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to print PDF: %w", err)
+	}
+	return data, nil
+}
+
+// printPageRanges splits the page ranges into the page numbers and range strings the print command takes
+func printPageRanges(ranges string) []interface{} {
+	var pages []interface{}
+	for _, part := range strings.Split(ranges, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// Parsed unsigned, so an open range such as "-3" stays a range
+		if page, err := strconv.ParseUint(part, 10, 0); err == nil {
+			pages = append(pages, int(page))
+		} else {
+			pages = append(pages, strings.ReplaceAll(part, " ", ""))
+		}
+	}
+	return pages
+}
@@ -0,0 +1,24 @@
+package browsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrintPageRanges(t *testing.T) {
+	tests := []struct {
+		ranges string
+		want   []interface{}
+	}{
+		{"", nil},
+		{"3", []interface{}{3}},
+		{"1-5, 8", []interface{}{"1-5", 8}},
+		{" 2 , 4 - 6 ,, 9-", []interface{}{2, "4-6", "9-"}},
+		{"-3,7", []interface{}{"-3", 7}},
+	}
+	for _, tt := range tests {
+		if got := printPageRanges(tt.ranges); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("printPageRanges(%q) = %#v, want %#v", tt.ranges, got, tt.want)
+		}
+	}
+}
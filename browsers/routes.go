@@ -0,0 +1,416 @@
+package browsers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Request is a network request caught by a route
+type Request struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	// Body is the posted data. WebDriver BiDi does not expose it, so it is empty in Firefox.
+	Body string
+}
+
+// Response is served to a routed request instead of the server's
+type Response struct {
+	// Status is the HTTP status code, 200 when zero
+	Status  int
+	Headers map[string]string
+	Body    string
+}
+
+// RequestOverride changes a request sent on to the server, empty fields keep the original values.
+// Headers replace all request headers, start from Request.Headers to change single ones.
+type RequestOverride struct {
+	URL     string
+	Method  string
+	Headers map[string]string
+	Body    string
+}
+
+// RouteMatch selects the requests a route handles, empty fields match every request
+type RouteMatch struct {
+	// URL is a glob matched against the full URL: "*" matches within a path segment and "**" across segments
+	URL string
+	// Regexp is matched against the full URL instead of URL when set
+	Regexp *regexp.Regexp
+	// Method is the HTTP method, matched case-insensitively
+	Method string
+}
+
+// RouteHandler decides what happens to a routed request
+type RouteHandler func(*Route)
+
+// Route is a paused request waiting for its handler.
+// The handler calls one of Fulfill, Continue or Abort, without a call the request goes on unchanged.
+type Route struct {
+	Request Request
+
+	action   routeAction
+	response Response
+	override RequestOverride
+}
+
+type routeAction int
+
+const (
+	routeContinue routeAction = iota
+	routeFulfill
+	routeAbort
+)
+
+// Fulfill answers the request with the response without contacting the server
+func (r *Route) Fulfill(res Response) {
+	r.action, r.response = routeFulfill, res
+}
+
+// Continue sends the request on to the server with the override applied
+func (r *Route) Continue(override RequestOverride) {
+	r.action, r.override = routeContinue, override
+}
+
+// Abort fails the request with a network error
+func (r *Route) Abort() {
+	r.action = routeAbort
+}
+
+// routeClient sends the requests fetched by route handlers
+var routeClient = &http.Client{Timeout: 60 * time.Second}
+
+// Fetch sends the request to the server from the test process and returns the response,
+// so the handler can change it and pass it to Fulfill. Cookies are only sent when the request headers hold them.
+func (r *Route) Fetch() (Response, error) {
+	req, err := http.NewRequest(r.Request.Method, r.Request.URL, strings.NewReader(r.Request.Body))
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to fetch %s: %w", r.Request.URL, err)
+	}
+	for name, value := range r.Request.Headers {
+		// Leave compression to the client, which then hands back the decoded body
+		if !strings.EqualFold(name, "Accept-Encoding") {
+			req.Header.Set(name, value)
+		}
+	}
+
+	resp, err := routeClient.Do(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to fetch %s: %w", r.Request.URL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to read response of %s: %w", r.Request.URL, err)
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	return Response{Status: resp.StatusCode, Headers: headers, Body: string(body)}, nil
+}
+
+// globRegexp translates a URL glob into a regular expression matching the full URL
+func globRegexp(glob string) *regexp.Regexp {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		default:
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+type routeEntry struct {
+	match   RouteMatch
+	url     *regexp.Regexp
+	handler RouteHandler
+}
+
+func (e *routeEntry) matches(req Request) bool {
+	if e.match.Method != "" && !strings.EqualFold(e.match.Method, req.Method) {
+		return false
+	}
+	return e.url == nil || e.url.MatchString(req.URL)
+}
+
+// routeState holds the routes of a browser.
+// Requests are handled from event goroutines, so access is guarded.
+type routeState struct {
+	mu     sync.Mutex
+	routes []*routeEntry
+}
+
+func (s *routeState) add(match RouteMatch, handler RouteHandler) {
+	entry := &routeEntry{match: match, url: match.Regexp, handler: handler}
+	if entry.url == nil && match.URL != "" {
+		entry.url = globRegexp(match.URL)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = append(s.routes, entry)
+}
+
+// remove drops the routes added with the match and returns how many routes are left
+func (s *routeState) remove(match RouteMatch) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.routes[:0]
+	for _, entry := range s.routes {
+		if entry.match != match {
+			kept = append(kept, entry)
+		}
+	}
+	s.routes = kept
+	return len(kept)
+}
+
+// handle passes the request to the newest route matching it, returning nil when none does
+func (s *routeState) handle(req Request) *Route {
+	s.mu.Lock()
+	var handler RouteHandler
+	for i := len(s.routes) - 1; i >= 0; i-- {
+		if s.routes[i].matches(req) {
+			handler = s.routes[i].handler
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if handler == nil {
+		return nil
+	}
+	route := &Route{Request: req}
+	handler(route)
+	return route
+}
+
+// Route passes the requests of every page in the browser context matching the match to the handler.
+// When several routes match a request the one added last handles it.
+func (c *Chrome) Route(match RouteMatch, handler RouteHandler) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	c.routes.add(match, handler)
+
+	c.routeMu.Lock()
+	started := c.routers != nil
+	if !started {
+		c.routers = map[proto.TargetTargetID]*rod.HijackRouter{}
+	}
+	c.routeMu.Unlock()
+	if started {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.routeMu.Lock()
+	c.stopNewPages = cancel
+	c.routeMu.Unlock()
+	if err := c.watchPages(ctx, c.hijackPage); err != nil {
+		c.stopRouting()
+		return fmt.Errorf("failed to intercept requests: %w", err)
+	}
+	return nil
+}
+
+// Unroute removes the routes added with the match, the requests they caught go on unchanged
+func (c *Chrome) Unroute(match RouteMatch) error {
+	if c.routes.remove(match) == 0 {
+		c.stopRouting()
+	}
+	return nil
+}
+
+// hijackPage intercepts the requests of the page, unless they already are
+func (c *Chrome) hijackPage(page *rod.Page) error {
+	c.routeMu.Lock()
+	defer c.routeMu.Unlock()
+	if c.routers == nil || c.routers[page.TargetID] != nil {
+		return nil
+	}
+
+	router := page.HijackRequests()
+	if err := router.Add("*", "", c.hijack); err != nil {
+		return chromeError(err)
+	}
+	c.routers[page.TargetID] = router
+	go router.Run()
+	return nil
+}
+
+// hijack passes a paused request to the routes and carries out their decision
+func (c *Chrome) hijack(h *rod.Hijack) {
+	route := c.routes.handle(Request{
+		Method:  h.Request.Method(),
+		URL:     h.Request.URL().String(),
+		Headers: chromeHeaders(h.Request.Headers()),
+		Body:    h.Request.Body(),
+	})
+	if route == nil {
+		h.ContinueRequest(&proto.FetchContinueRequest{})
+		return
+	}
+
+	switch route.action {
+	case routeFulfill:
+		payload := h.Response.Payload()
+		payload.ResponseCode = route.response.Status
+		if payload.ResponseCode == 0 {
+			payload.ResponseCode = http.StatusOK
+		}
+		payload.ResponseHeaders = fetchHeaders(route.response.Headers)
+		payload.Body = []byte(route.response.Body)
+	case routeAbort:
+		h.Response.Fail(proto.NetworkErrorReasonFailed)
+	default:
+		override := route.override
+		continued := &proto.FetchContinueRequest{URL: override.URL, Method: override.Method}
+		if override.Body != "" {
+			continued.PostData = []byte(override.Body)
+		}
+		if override.Headers != nil {
+			continued.Headers = fetchHeaders(override.Headers)
+		}
+		h.ContinueRequest(continued)
+	}
+}
+
+// stopRouting stops intercepting requests, releasing the paused ones
+func (c *Chrome) stopRouting() {
+	c.routeMu.Lock()
+	defer c.routeMu.Unlock()
+	if c.stopNewPages != nil {
+		c.stopNewPages()
+		c.stopNewPages = nil
+	}
+	for _, router := range c.routers {
+		// Fails only for pages closed meanwhile
+		router.Stop()
+	}
+	c.routers = nil
+}
+
+// fetchHeaders lists the headers for the Fetch domain, repeating those Chrome joined with newlines
+func fetchHeaders(headers map[string]string) []*proto.FetchHeaderEntry {
+	entries := make([]*proto.FetchHeaderEntry, 0, len(headers))
+	for name, value := range headers {
+		for _, line := range strings.Split(value, "\n") {
+			entries = append(entries, &proto.FetchHeaderEntry{Name: name, Value: line})
+		}
+	}
+	return entries
+}
+
+// Route passes the requests of the session matching the match to the handler.
+// When several routes match a request the one added last handles it.
+// Interception relies on WebDriver BiDi, Safari offers none and reports ErrUnsupported.
+func (b *webDriverBrowser) Route(match RouteMatch, handler RouteHandler) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	if b.bidi == nil {
+		return fmt.Errorf("request interception needs WebDriver BiDi, which %s does not offer: %w", b.name, ErrUnsupported)
+	}
+	b.routes.add(match, handler)
+	if b.intercept != "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	if !b.routeEvents {
+		b.bidi.OnBeforeRequestSent(func(e webdriver.BeforeRequestSent) {
+			if e.IsBlocked {
+				// Handlers may take a while, other requests are not held up meanwhile
+				go b.interceptRequest(e)
+			}
+		})
+		if err := b.bidi.Subscribe(ctx, "network.beforeRequestSent"); err != nil {
+			return fmt.Errorf("failed to intercept requests: %w", err)
+		}
+		b.routeEvents = true
+	}
+	intercept, err := b.bidi.AddIntercept(ctx, webdriver.PhaseBeforeRequestSent)
+	if err != nil {
+		return fmt.Errorf("failed to intercept requests: %w", err)
+	}
+	b.intercept = intercept
+	return nil
+}
+
+// Unroute removes the routes added with the match, the requests they caught go on unchanged
+func (b *webDriverBrowser) Unroute(match RouteMatch) error {
+	if b.routes.remove(match) > 0 || b.intercept == "" {
+		return nil
+	}
+	if err := b.bidi.RemoveIntercept(context.Background(), b.intercept); err != nil {
+		return fmt.Errorf("failed to stop intercepting requests: %w", err)
+	}
+	b.intercept = ""
+	return nil
+}
+
+// interceptRequest passes a paused request to the routes and carries out their decision
+func (b *webDriverBrowser) interceptRequest(e webdriver.BeforeRequestSent) {
+	route := b.routes.handle(Request{Method: e.Request.Method, URL: e.Request.URL, Headers: bidiHeaderMap(e.Request.Headers)})
+	if route == nil {
+		route = &Route{}
+	}
+
+	// Fails only when the page went away together with the request
+	ctx := context.Background()
+	id := e.Request.Request
+	switch route.action {
+	case routeFulfill:
+		status := route.response.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		b.bidi.ProvideResponse(ctx, webdriver.ProvideResponse{
+			Request:      id,
+			StatusCode:   status,
+			ReasonPhrase: http.StatusText(status),
+			Headers:      bidiHeaders(route.response.Headers),
+			Body:         webdriver.StringValue(route.response.Body),
+		})
+	case routeAbort:
+		b.bidi.FailRequest(ctx, id)
+	default:
+		override := route.override
+		continued := webdriver.ContinueRequest{Request: id, URL: override.URL, Method: override.Method}
+		if override.Body != "" {
+			continued.Body = webdriver.StringValue(override.Body)
+		}
+		if override.Headers != nil {
+			continued.Headers = bidiHeaders(override.Headers)
+		}
+		b.bidi.ContinueRequest(ctx, continued)
+	}
+}
+
+func bidiHeaders(headers map[string]string) []webdriver.Header {
+	list := make([]webdriver.Header, 0, len(headers))
+	for name, value := range headers {
+		list = append(list, webdriver.Header{Name: name, Value: *webdriver.StringValue(value)})
+	}
+	return list
+}
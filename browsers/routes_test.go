@@ -0,0 +1,72 @@
+package browsers
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		url   string
+		match bool
+	}{
+		{"https://example.com/api/users", "https://example.com/api/users", true},
+		{"https://example.com/api/users", "https://example.com/api/users/1", false},
+		{"https://example.com/api/*", "https://example.com/api/users", true},
+		{"https://example.com/api/*", "https://example.com/api/users/1", false},
+		{"**/api/**", "https://example.com/api/users/1", true},
+		{"**/*.png", "https://cdn.example.com/img/logo.png", true},
+		{"**/*.png", "https://cdn.example.com/img/logo.png?v=2", false},
+		{"https://example.com/search?q=*", "https://example.com/search?q=owl", true},
+		{"https://example.com/a.b", "https://example.com/axb", false},
+		{"**", "about:blank", true},
+	}
+	for _, tt := range tests {
+		if got := globRegexp(tt.glob).MatchString(tt.url); got != tt.match {
+			t.Errorf("globRegexp(%q).MatchString(%q) = %v, want %v", tt.glob, tt.url, got, tt.match)
+		}
+	}
+}
+
+func TestRouteStateHandle(t *testing.T) {
+	var routes routeState
+	handled := ""
+	routes.add(RouteMatch{URL: "**/api/**"}, func(r *Route) { handled = "api"; r.Abort() })
+	routes.add(RouteMatch{URL: "**/api/users", Method: "POST"}, func(r *Route) { handled = "post users"; r.Fulfill(Response{Status: 201}) })
+	routes.add(RouteMatch{Regexp: regexp.MustCompile(`\.css$`)}, func(r *Route) { handled = "css"; r.Continue(RequestOverride{}) })
+
+	tests := []struct {
+		req     Request
+		handled string
+		action  routeAction
+	}{
+		{Request{Method: "GET", URL: "https://example.com/api/users"}, "api", routeAbort},
+		{Request{Method: "post", URL: "https://example.com/api/users"}, "post users", routeFulfill},
+		{Request{Method: "GET", URL: "https://example.com/site.css"}, "css", routeContinue},
+		{Request{Method: "GET", URL: "https://example.com/index.html"}, "", 0},
+	}
+	for _, tt := range tests {
+		handled = ""
+		route := routes.handle(tt.req)
+		if handled != tt.handled {
+			t.Errorf("%s %s handled by %q, want %q", tt.req.Method, tt.req.URL, handled, tt.handled)
+		}
+		if tt.handled == "" {
+			if route != nil {
+				t.Errorf("%s %s got a route, want none", tt.req.Method, tt.req.URL)
+			}
+			continue
+		}
+		if route == nil || route.action != tt.action {
+			t.Errorf("%s %s route = %+v, want action %d", tt.req.Method, tt.req.URL, route, tt.action)
+		}
+	}
+
+	if left := routes.remove(RouteMatch{URL: "**/api/**"}); left != 2 {
+		t.Errorf("remove() left %d routes, want 2", left)
+	}
+	if route := routes.handle(Request{Method: "GET", URL: "https://example.com/api/users"}); route != nil {
+		t.Error("removed route still handles requests")
+	}
+}
@@ -0,0 +1,133 @@
+package browsers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// Screenshot captures the visible viewport of the current page as PNG
+func (c *Chrome) Screenshot() ([]byte, error) {
+	if c.Page == nil {
+		return nil, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	data, err := c.Page.Screenshot(false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", chromeError(err))
+	}
+	return data, nil
+}
+
+// FullPageScreenshot captures the whole scrollable page as PNG
+func (c *Chrome) FullPageScreenshot() ([]byte, error) {
+	if c.Page == nil {
+		return nil, fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	data, err := c.Page.Screenshot(true, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture full page screenshot: %w", chromeError(err))
+	}
+	return data, nil
+}
+
+func (e *chromeElement) Screenshot() ([]byte, error) {
+	data, err := e.el.Screenshot("png", 0)
+	return data, chromeError(err)
+}
+
+// Screenshot captures the visible viewport of the current page as PNG
+func (b *webDriverBrowser) Screenshot() ([]byte, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	data, err := b.session.Screenshot(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+	return data, nil
+}
+
+// FullPageScreenshot captures the whole scrollable page as PNG.
+// Drivers without a native command get the page stitched together from viewport captures.
+func (b *webDriverBrowser) FullPageScreenshot() ([]byte, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+
+	data, err := b.session.FullPageScreenshot(ctx)
+	if errors.Is(err, webdriver.ErrUnknownCommand) || errors.Is(err, webdriver.ErrUnknownMethod) || errors.Is(err, webdriver.ErrUnsupportedOperation) {
+		data, err = stitchScreenshot(ctx, b.session)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture full page screenshot: %w", err)
+	}
+	return data, nil
+}
+
+func (e *webDriverElement) Screenshot() ([]byte, error) {
+	return e.el.Screenshot(e.ctx)
+}
+
+// stitchScreenshot scrolls through the document one viewport at a time and joins the captures into a single PNG
+func stitchScreenshot(ctx context.Context, session *webdriver.Session) ([]byte, error) {
+	var page struct {
+		Width    float64 `json:"width"`
+		Height   float64 `json:"height"`
+		Viewport float64 `json:"viewport"`
+		ScrollX  float64 `json:"scrollX"`
+		ScrollY  float64 `json:"scrollY"`
+	}
+	script := `const el = document.scrollingElement || document.documentElement;
+		return {width: window.innerWidth, height: el.scrollHeight, viewport: window.innerHeight, scrollX: window.scrollX, scrollY: window.scrollY}`
+	if err := session.ExecuteScript(ctx, script, nil, &page); err != nil {
+		return nil, err
+	}
+	// A minimized window, collapsed frame or empty document has nothing to scroll through and scale to
+	if page.Viewport <= 0 || page.Width <= 0 {
+		return session.Screenshot(ctx)
+	}
+	defer session.ExecuteScript(ctx, `window.scrollTo(arguments[0], arguments[1])`, []interface{}{page.ScrollX, page.ScrollY}, nil)
+
+	var canvas *image.RGBA
+	var scale float64
+	for offset := 0.0; offset < page.Height; offset += page.Viewport {
+		var scrolled float64
+		if err := session.ExecuteScript(ctx, `window.scrollTo(0, arguments[0]); return window.scrollY`, []interface{}{offset}, &scrolled); err != nil {
+			return nil, err
+		}
+
+		data, err := session.Screenshot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		shot, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+		}
+
+		if canvas == nil {
+			// Captures are in device pixels, the page metrics in CSS pixels
+			scale = float64(shot.Bounds().Dx()) / page.Width
+			canvas = image.NewRGBA(image.Rect(0, 0, shot.Bounds().Dx(), int(math.Ceil(page.Height*scale))))
+		}
+		top := int(math.Round(scrolled * scale))
+		draw.Draw(canvas, shot.Bounds().Add(image.Pt(0, top)), shot, shot.Bounds().Min, draw.Src)
+	}
+	if canvas == nil {
+		return session.Screenshot(ctx)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode screenshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,524 @@
+package browsers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// StorageState is the authenticated state of a browser: its cookies and the web storage of each origin
+type StorageState struct {
+	Cookies []Cookie      `json:"cookies"`
+	Origins []OriginState `json:"origins"`
+}
+
+// OriginState holds the web storage of a single origin such as "https://example.com"
+type OriginState struct {
+	Origin         string            `json:"origin"`
+	LocalStorage   map[string]string `json:"localStorage,omitempty"`
+	SessionStorage map[string]string `json:"sessionStorage,omitempty"`
+}
+
+// ReadStorageState loads a storage state file written by SaveStorageState
+func ReadStorageState(path string) (*StorageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage state: %w", err)
+	}
+	var state StorageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse storage state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// WriteFile saves the storage state as JSON, readable only by the current user as it holds credentials
+func (s *StorageState) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode storage state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write storage state: %w", err)
+	}
+	return nil
+}
+
+// originSet collects the http(s) origins visited in a browser context, in the order they were first seen.
+// Chrome adds them from event goroutines, so access is guarded.
+type originSet struct {
+	mu      sync.Mutex
+	seen    map[string]bool
+	origins []string
+}
+
+// add records the origin of the URL, ignoring URLs without an http(s) origin
+func (s *originSet) add(rawURL string) {
+	origin := urlOrigin(rawURL)
+	if origin == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[origin] {
+		return
+	}
+	if s.seen == nil {
+		s.seen = map[string]bool{}
+	}
+	s.seen[origin] = true
+	s.origins = append(s.origins, origin)
+}
+
+func (s *originSet) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.origins...)
+}
+
+// urlOrigin returns the origin of the URL as location.origin spells it, empty when it is not http(s)
+func urlOrigin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	host := strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		host = strings.ToLower(u.Hostname())
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+	}
+	return u.Scheme + "://" + host
+}
+
+// pageBrowser is the part of Browser needed to visit origins and reach their web storage
+type pageBrowser interface {
+	evaluator
+	OpenURL(url string) error
+}
+
+// currentOrigin returns the web storage of the current page, or nil when it has no http(s) origin
+func currentOrigin(b pageBrowser) (*OriginState, error) {
+	value, err := b.Evaluate(`() => location.origin`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page origin: %w", err)
+	}
+	origin, _ := value.(string)
+	if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+		return nil, nil
+	}
+
+	state := &OriginState{Origin: origin}
+	if state.LocalStorage, err = storageItems(b, LocalStorage); err != nil {
+		return nil, err
+	}
+	if state.SessionStorage, err = storageItems(b, SessionStorage); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// restoreOrigins visits every origin and writes its web storage, leaving the page blank afterwards
+func restoreOrigins(b pageBrowser, origins []OriginState) error {
+	for _, origin := range origins {
+		if len(origin.LocalStorage) == 0 && len(origin.SessionStorage) == 0 {
+			continue
+		}
+		if err := visitOrigin(b, origin.Origin); err != nil {
+			return err
+		}
+		for key, value := range origin.LocalStorage {
+			if err := setStorageItem(b, LocalStorage, key, value); err != nil {
+				return err
+			}
+		}
+		for key, value := range origin.SessionStorage {
+			if err := setStorageItem(b, SessionStorage, key, value); err != nil {
+				return err
+			}
+		}
+	}
+	if len(origins) > 0 {
+		return b.OpenURL("about:blank")
+	}
+	return nil
+}
+
+// visitOrigin opens the root of the origin and fails if the page ends up on another origin
+func visitOrigin(b pageBrowser, origin string) error {
+	if err := b.OpenURL(origin + "/"); err != nil {
+		return err
+	}
+	value, err := b.Evaluate(`() => location.origin`)
+	if err != nil {
+		return fmt.Errorf("failed to read page origin: %w", err)
+	}
+	if value != origin {
+		return fmt.Errorf("opening %s redirected to %v, cannot restore its storage", origin, value)
+	}
+	return nil
+}
+
+// SaveStorageState writes all cookies and the web storage of every visited origin to the file
+func (c *Chrome) SaveStorageState(path string) error {
+	state, err := c.StorageState()
+	if err != nil {
+		return err
+	}
+	return state.WriteFile(path)
+}
+
+// StorageState returns all cookies of the browser and the web storage of every origin visited in the context.
+// Origins open in a page are read from it. Origins no longer open are opened in a temporary page,
+// which has their local storage only, as session storage ends with the page.
+func (c *Chrome) StorageState() (*StorageState, error) {
+	if c.Browser == nil {
+		return nil, fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	res, err := proto.StorageGetCookies{BrowserContextID: c.Browser.BrowserContextID}.Call(c.Browser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cookies: %w", chromeError(err))
+	}
+
+	origins, err := c.originStorage()
+	if err != nil {
+		return nil, err
+	}
+	return &StorageState{Cookies: chromeCookies(res.Cookies), Origins: origins}, nil
+}
+
+// trackOrigins records the origins the frames of every page in the browser context navigate to until Close
+func (c *Chrome) trackOrigins() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopOrigins = cancel
+	var mu sync.Mutex
+	tracked := map[proto.TargetTargetID]bool{}
+	err := c.watchPages(ctx, func(page *rod.Page) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if tracked[page.TargetID] {
+			return nil
+		}
+		tracked[page.TargetID] = true
+		wait := page.Context(ctx).EachEvent(func(e *proto.PageFrameNavigated) {
+			c.visited.add(e.Frame.SecurityOrigin)
+		})
+		go wait()
+		// Frames that navigated before the page was attached
+		for _, origin := range chromeFrameOrigins(page) {
+			c.visited.add(origin)
+		}
+		return nil
+	})
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to track origins: %w", err)
+	}
+	return nil
+}
+
+// originStorage reads the web storage of every visited origin, those open in the current page first
+func (c *Chrome) originStorage() ([]OriginState, error) {
+	pages, err := c.Pages()
+	if err != nil {
+		return nil, err
+	}
+	if c.Page != nil {
+		for i, id := range pages {
+			if id == PageID(c.Page.TargetID) {
+				pages[0], pages[i] = pages[i], pages[0]
+			}
+		}
+	}
+
+	origins := []OriginState{}
+	read := map[string]bool{}
+	for _, id := range pages {
+		page, err := c.Browser.PageFromTarget(proto.TargetTargetID(id))
+		if err != nil {
+			continue
+		}
+		for _, origin := range chromeFrameOrigins(page) {
+			c.visited.add(origin)
+			if read[origin] {
+				continue
+			}
+			state, err := chromeOriginStorage(page, origin, true)
+			if err != nil {
+				// Frames of other sites run in processes of their own, the temporary page reads them below
+				continue
+			}
+			read[origin] = true
+			origins = appendOrigin(origins, state)
+		}
+	}
+
+	var page *rod.Page
+	for _, origin := range c.visited.list() {
+		if read[origin] {
+			continue
+		}
+		if page == nil {
+			if page, err = c.Browser.Page(proto.TargetCreateTarget{}); err != nil {
+				return nil, fmt.Errorf("failed to open page: %w", chromeError(err))
+			}
+			defer page.Close()
+		}
+		if err := page.Navigate(origin + "/"); err != nil {
+			log.Printf("Failed to open %s, its storage is not saved: %v\n", origin, err)
+			continue
+		}
+		state, err := chromeOriginStorage(page, origin, false)
+		if err != nil {
+			log.Printf("Failed to read the storage of %s, it is not saved: %v\n", origin, err)
+			continue
+		}
+		origins = appendOrigin(origins, state)
+	}
+	return origins, nil
+}
+
+// chromeFrameOrigins returns the http(s) origins of the page's frames
+func chromeFrameOrigins(page *rod.Page) []string {
+	tree, err := proto.PageGetFrameTree{}.Call(page)
+	if err != nil {
+		return nil
+	}
+	var origins []string
+	var walk func(*proto.PageFrameTree)
+	walk = func(node *proto.PageFrameTree) {
+		if origin := urlOrigin(node.Frame.SecurityOrigin); origin != "" {
+			origins = append(origins, origin)
+		}
+		for _, child := range node.ChildFrames {
+			walk(child)
+		}
+	}
+	walk(tree.FrameTree)
+	return origins
+}
+
+// chromeOriginStorage reads the local storage, and with session set the session storage, of an origin open in the page
+func chromeOriginStorage(page *rod.Page, origin string, session bool) (OriginState, error) {
+	state := OriginState{Origin: origin}
+	var err error
+	if state.LocalStorage, err = chromeStorageItems(page, origin, true); err != nil {
+		return state, err
+	}
+	if session {
+		if state.SessionStorage, err = chromeStorageItems(page, origin, false); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+func chromeStorageItems(page *rod.Page, origin string, local bool) (map[string]string, error) {
+	res, err := proto.DOMStorageGetDOMStorageItems{
+		StorageID: &proto.DOMStorageStorageID{SecurityOrigin: origin, IsLocalStorage: local},
+	}.Call(page)
+	if err != nil {
+		return nil, chromeError(err)
+	}
+	items := make(map[string]string, len(res.Entries))
+	for _, entry := range res.Entries {
+		if len(entry) == 2 {
+			items[entry[0]] = entry[1]
+		}
+	}
+	return items, nil
+}
+
+// appendOrigin adds the origin's storage to the list unless it has none
+func appendOrigin(origins []OriginState, state OriginState) []OriginState {
+	if len(state.LocalStorage) == 0 && len(state.SessionStorage) == 0 {
+		return origins
+	}
+	return append(origins, state)
+}
+
+// LoadStorageState restores cookies and web storage from a file written by SaveStorageState
+func (c *Chrome) LoadStorageState(path string) error {
+	state, err := ReadStorageState(path)
+	if err != nil {
+		return err
+	}
+	return c.SetStorageState(state)
+}
+
+// SetStorageState restores cookies and web storage, visiting each stored origin to write its storage
+func (c *Chrome) SetStorageState(state *StorageState) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	if len(state.Cookies) > 0 {
+		err := proto.StorageSetCookies{
+			Cookies:          chromeCookieParams(state.Cookies),
+			BrowserContextID: c.Browser.BrowserContextID,
+		}.Call(c.Browser)
+		if err != nil {
+			return fmt.Errorf("failed to set cookies: %w", chromeError(err))
+		}
+	}
+	return restoreOrigins(c, state.Origins)
+}
+
+// SaveStorageState writes the cookies and the web storage of every visited origin to the file
+func (b *webDriverBrowser) SaveStorageState(path string) error {
+	state, err := b.StorageState()
+	if err != nil {
+		return err
+	}
+	return state.WriteFile(path)
+}
+
+// StorageState returns the cookies and web storage of the current page and the web storage of every other
+// origin opened through OpenURL or holding one of the cookies. WebDriver only exposes the cookies and storage
+// of the current page, so each other origin is visited and the current page is opened again afterwards.
+func (b *webDriverBrowser) StorageState() (*StorageState, error) {
+	cookies, err := b.Cookies()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	current, err := b.session.CurrentURL(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page URL: %w", err)
+	}
+
+	state := &StorageState{Cookies: cookies, Origins: []OriginState{}}
+	origin, err := currentOrigin(b)
+	if err != nil {
+		return nil, err
+	}
+	if origin != nil {
+		state.Origins = appendOrigin(state.Origins, *origin)
+	}
+
+	// Cookie domains have no port, it is taken from the visited origins
+	var known []OriginState
+	for _, visited := range b.visited.list() {
+		known = append(known, OriginState{Origin: visited})
+	}
+	others := b.visited.list()
+	for _, cookie := range cookies {
+		others = append(others, cookieOrigin(strings.TrimPrefix(cookie.Domain, "."), cookie.Secure, known).String())
+	}
+
+	read := map[string]bool{urlOrigin(current): true}
+	for _, other := range others {
+		other = urlOrigin(other)
+		if other == "" || read[other] {
+			continue
+		}
+		read[other] = true
+		if err := visitOrigin(b, other); err != nil {
+			log.Printf("Failed to open %s, its storage is not saved: %v\n", other, err)
+			continue
+		}
+		origin, err := currentOrigin(b)
+		if err != nil {
+			return nil, err
+		}
+		if origin != nil {
+			state.Origins = appendOrigin(state.Origins, *origin)
+		}
+	}
+	if len(read) > 1 {
+		if err := b.OpenURL(current); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// LoadStorageState restores cookies and web storage from a file written by SaveStorageState
+func (b *webDriverBrowser) LoadStorageState(path string) error {
+	state, err := ReadStorageState(path)
+	if err != nil {
+		return err
+	}
+	return b.SetStorageState(state)
+}
+
+// SetStorageState restores cookies and web storage.
+// WebDriver only sets cookies for the current page, so each cookie domain is visited first,
+// on the scheme and port of its stored origin when there is one.
+func (b *webDriverBrowser) SetStorageState(state *StorageState) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+
+	var hosts []string
+	byHost := map[string][]Cookie{}
+	for _, cookie := range state.Cookies {
+		host := strings.TrimPrefix(cookie.Domain, ".")
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], cookie)
+	}
+	for _, host := range hosts {
+		cookies := byHost[host]
+		secure := false
+		for _, cookie := range cookies {
+			secure = secure || cookie.Secure
+		}
+		u := cookieOrigin(host, secure, state.Origins)
+		if err := b.OpenURL(u.String()); err != nil {
+			return fmt.Errorf("failed to open %s to set its cookies: %w", host, err)
+		}
+		if err := b.SetCookies(cookies...); err != nil {
+			return err
+		}
+	}
+
+	if err := restoreOrigins(b, state.Origins); err != nil {
+		return err
+	}
+	if len(hosts) > 0 && len(state.Origins) == 0 {
+		return b.OpenURL("about:blank")
+	}
+	return nil
+}
+
+// cookieOrigin returns the root URL to set the cookies of the host from. Cookie domains have no port,
+// so it is taken with the scheme from a stored origin on the host or its subdomains, secure cookies
+// preferring an https one. Without a matching origin the bare host is used.
+func cookieOrigin(host string, secure bool, origins []OriginState) *url.URL {
+	var match *url.URL
+	for _, origin := range origins {
+		u, err := url.Parse(origin.Origin)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		hostname := u.Hostname()
+		if hostname != host && !strings.HasSuffix(hostname, "."+host) {
+			continue
+		}
+		// An exact host beats a subdomain, and for secure cookies https beats http
+		better := match == nil ||
+			(hostname == host && match.Hostname() != host) ||
+			(secure && u.Scheme == "https" && match.Scheme != "https" && (hostname == host) == (match.Hostname() == host))
+		if better {
+			match = u
+		}
+	}
+	if match != nil {
+		return &url.URL{Scheme: match.Scheme, Host: match.Host, Path: "/"}
+	}
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: host, Path: "/"}
+}
@@ -0,0 +1,88 @@
+package browsers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCookieOrigin(t *testing.T) {
+	origins := []OriginState{
+		{Origin: "http://localhost:3000"},
+		{Origin: "http://app.example.com:8080"},
+		{Origin: "https://app.example.com"},
+		{Origin: "http://example.com"},
+		{Origin: "not a url"},
+	}
+	tests := []struct {
+		name    string
+		host    string
+		secure  bool
+		origins []OriginState
+		want    string
+	}{
+		{"port from origin", "localhost", false, origins, "http://localhost:3000/"},
+		{"exact host beats subdomain", "example.com", false, origins, "http://example.com/"},
+		{"subdomain when no exact host", "app.example.com", false, origins, "http://app.example.com:8080/"},
+		{"https for secure cookies", "app.example.com", true, origins, "https://app.example.com/"},
+		{"exact host beats https subdomain", "example.com", true, origins, "http://example.com/"},
+		{"bare host without origin", "other.test", false, origins, "http://other.test/"},
+		{"bare https host for secure cookies", "other.test", true, nil, "https://other.test/"},
+		{"suffix is not a subdomain", "ample.com", false, origins, "http://ample.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cookieOrigin(tt.host, tt.secure, tt.origins).String(); got != tt.want {
+				t.Errorf("cookieOrigin(%q, %v) = %q, want %q", tt.host, tt.secure, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLOrigin(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/login?next=/", "https://example.com"},
+		{"http://localhost:3000/app#top", "http://localhost:3000"},
+		{"https://Example.COM:443/", "https://example.com"},
+		{"http://example.com:80", "http://example.com"},
+		{"https://example.com:80", "https://example.com:80"},
+		{"http://[::1]:80/", "http://[::1]"},
+		{"about:blank", ""},
+		{"data:text/html,<p>", ""},
+		{"file:///tmp/page.html", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := urlOrigin(tt.url); got != tt.want {
+			t.Errorf("urlOrigin(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestOriginSet(t *testing.T) {
+	var set originSet
+	for _, url := range []string{
+		"https://example.com/login",
+		"about:blank",
+		"https://sso.example.net/auth",
+		"https://example.com/home",
+		"http://localhost:3000/",
+	} {
+		set.add(url)
+	}
+	want := []string{"https://example.com", "https://sso.example.net", "http://localhost:3000"}
+	if got := set.list(); !reflect.DeepEqual(got, want) {
+		t.Errorf("list() = %v, want %v", got, want)
+	}
+}
+
+func TestAppendOrigin(t *testing.T) {
+	origins := appendOrigin(nil, OriginState{Origin: "https://empty.example"})
+	origins = appendOrigin(origins, OriginState{Origin: "https://example.com", LocalStorage: map[string]string{"token": "t"}})
+	origins = appendOrigin(origins, OriginState{Origin: "https://tab.example", SessionStorage: map[string]string{"step": "2"}})
+	if len(origins) != 2 || origins[0].Origin != "https://example.com" || origins[1].Origin != "https://tab.example" {
+		t.Errorf("appendOrigin() kept %+v, want the two origins with storage", origins)
+	}
+}
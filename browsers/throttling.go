@@ -0,0 +1,101 @@
+package browsers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// NetworkConditions describes an emulated network.
+// Throughputs are in bytes per second, zero leaves them unlimited.
+type NetworkConditions struct {
+	Offline bool
+	// Latency is added to every request
+	Latency            time.Duration
+	DownloadThroughput float64
+	UploadThroughput   float64
+}
+
+// NetworkProfiles are the built-in network conditions by name, matching the Chrome DevTools presets
+var NetworkProfiles = map[string]NetworkConditions{
+	"no throttling": {},
+	"offline":       {Offline: true},
+	"slow 3G":       {Latency: 2000 * time.Millisecond, DownloadThroughput: 500 * 1000 / 8 * 0.8, UploadThroughput: 500 * 1000 / 8 * 0.8},
+	"fast 3G":       {Latency: 562500 * time.Microsecond, DownloadThroughput: 1600 * 1000 / 8 * 0.9, UploadThroughput: 750 * 1000 / 8 * 0.9},
+}
+
+// LookupNetworkProfile returns the built-in network conditions with the name
+func LookupNetworkProfile(name string) (NetworkConditions, error) {
+	conditions, ok := NetworkProfiles[name]
+	if !ok {
+		names := make([]string, 0, len(NetworkProfiles))
+		for known := range NetworkProfiles {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return NetworkConditions{}, fmt.Errorf("unknown network profile %q, known profiles are %q", name, names)
+	}
+	return conditions, nil
+}
+
+// throughput returns the CDP throughput, where -1 disables throttling
+func throughput(bytesPerSecond float64) float64 {
+	if bytesPerSecond <= 0 {
+		return -1
+	}
+	return bytesPerSecond
+}
+
+// ThrottleNetwork makes the current page's requests go through the network conditions,
+// the zero NetworkConditions restore the real network
+func (c *Chrome) ThrottleNetwork(conditions NetworkConditions) error {
+	if c.Page == nil {
+		return fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	// The conditions apply to the network domain of the page, which must be enabled
+	if err := (proto.NetworkEnable{}).Call(c.Page); err != nil {
+		return fmt.Errorf("failed to throttle network: %w", chromeError(err))
+	}
+	err := proto.NetworkEmulateNetworkConditions{
+		Offline:            conditions.Offline,
+		Latency:            float64(conditions.Latency) / float64(time.Millisecond),
+		DownloadThroughput: throughput(conditions.DownloadThroughput),
+		UploadThroughput:   throughput(conditions.UploadThroughput),
+	}.Call(c.Page)
+	if err != nil {
+		return fmt.Errorf("failed to throttle network: %w", chromeError(err))
+	}
+	return nil
+}
+
+// ThrottleCPU slows the current page's CPU down by the rate, such as 4 for a mid-range phone, 1 restores full speed
+func (c *Chrome) ThrottleCPU(rate float64) error {
+	if c.Page == nil {
+		return fmt.Errorf("no page opened: %w", ErrInvalidSessionID)
+	}
+	if rate < 1 {
+		return fmt.Errorf("CPU throttling rate %v is below 1: %w", rate, ErrInvalidArgument)
+	}
+	if err := (proto.EmulationSetCPUThrottlingRate{Rate: rate}).Call(c.Page); err != nil {
+		return fmt.Errorf("failed to throttle CPU: %w", chromeError(err))
+	}
+	return nil
+}
+
+// ThrottleNetwork is unsupported, WebDriver has no command to shape the network
+func (b *webDriverBrowser) ThrottleNetwork(conditions NetworkConditions) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	return fmt.Errorf("network throttling needs the Chrome DevTools Protocol, which %s does not offer: %w", b.name, ErrUnsupported)
+}
+
+// ThrottleCPU is unsupported, WebDriver has no command to slow the CPU down
+func (b *webDriverBrowser) ThrottleCPU(rate float64) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	return fmt.Errorf("CPU throttling needs the Chrome DevTools Protocol, which %s does not offer: %w", b.name, ErrUnsupported)
+}
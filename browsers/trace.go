@@ -0,0 +1,644 @@
+package browsers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Files of a trace archive
+const (
+	traceIndexFile   = "trace.json"
+	traceNetworkFile = "network.har"
+)
+
+// Trace is the index of a trace archive, the zip file a Tracer saves.
+// Snapshots, screenshots and the network log are separate files of the archive, referenced by their paths.
+type Trace struct {
+	Start   time.Time     `json:"start"`
+	End     time.Time     `json:"end"`
+	Actions []TraceAction `json:"actions"`
+	// Network is the HAR of the traffic during the trace, empty when the browser could not record it
+	Network string `json:"network,omitempty"`
+}
+
+// TraceAction is a call made through the Browser API with the page state around it
+type TraceAction struct {
+	Name   string    `json:"name"`
+	Params []string  `json:"params,omitempty"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Error  string    `json:"error,omitempty"`
+	// URL is the address of the page after the action
+	URL string `json:"url,omitempty"`
+	// Before and After are the DOM snapshots taken around the action, Screenshot the page after it
+	Before     string     `json:"before,omitempty"`
+	After      string     `json:"after,omitempty"`
+	Screenshot string     `json:"screenshot,omitempty"`
+	Console    []traceLog `json:"console,omitempty"`
+	PageErrors []traceLog `json:"pageErrors,omitempty"`
+}
+
+// traceLog is a console message or page error logged during an action
+type traceLog struct {
+	Level    ConsoleLevel `json:"level,omitempty"`
+	Text     string       `json:"text"`
+	Location string       `json:"location,omitempty"`
+}
+
+// Tracer wraps a browser and records every action performed through it, with the element actions of
+// the elements it finds, into a trace archive. Open an archive with "owl show-trace <file>".
+//
+// The actions are navigation, opening, switching and closing pages and frames, finding and waiting,
+// input, scripts and every method changing the state of the browser context: the window, emulation,
+// permissions, routes, HAR recording, throttling, cookies and storage. Methods that only read state,
+// such as Cookies or Screenshot, and the handler and wait settings are not recorded.
+// Contexts made by NewContext are not traced.
+//
+// The network log shares the browser's HAR recording, so recordHar and StartHAR and StopHAR
+// called through the tracer work alongside it.
+type Tracer struct {
+	Browser
+
+	mu       sync.Mutex
+	trace    Trace
+	files    map[string][]byte
+	console  int // console messages and page errors already added to an action
+	errors   int
+	harPath  string      // temporary file the tracer records the network into, empty when it records none itself
+	sharing  bool        // whether the tracer shares a HAR recording the browser was already making
+	network  []*HAREntry // entries of the recordings that ended during the trace
+	userHAR  string      // where StopHAR writes the HAR started through the tracer, empty when none
+	userFrom int         // first network entry of that HAR
+	traceDir string      // where Close saves the archive, empty to leave saving to the caller
+}
+
+// harSource is a browser whose HAR recording can be read while it goes on
+type harSource interface {
+	harEntries() ([]*HAREntry, bool)
+}
+
+// NewTracer starts tracing the launched browser
+func NewTracer(browser Browser) *Tracer {
+	t := &Tracer{Browser: browser, files: map[string][]byte{}}
+	t.trace.Start = time.Now()
+	t.console, t.errors = len(browser.ConsoleMessages()), len(browser.PageErrors())
+	t.startNetwork()
+	return t
+}
+
+// startNetwork records the network for the trace. A recording the browser is already making, such as that
+// of recordHar, is shared, otherwise the tracer records into a temporary file of its own.
+func (t *Tracer) startNetwork() {
+	source, ok := t.Browser.(harSource)
+	if !ok {
+		log.Println("Trace has no network log: the browser cannot share its HAR recording")
+		return
+	}
+	if _, recording := source.harEntries(); recording {
+		t.sharing = true
+		return
+	}
+	file, err := os.CreateTemp("", "owl-trace-*.har")
+	if err == nil {
+		file.Close()
+		if err = t.Browser.StartHAR(file.Name()); err == nil {
+			t.harPath = file.Name()
+		} else {
+			os.Remove(file.Name())
+		}
+	}
+	if err != nil {
+		log.Println("Trace has no network log:", err)
+	}
+}
+
+// networkEntries returns the network entries of the trace so far
+func (t *Tracer) networkEntries() []*HAREntry {
+	entries := append([]*HAREntry(nil), t.network...)
+	if source, ok := t.Browser.(harSource); ok {
+		current, _ := source.harEntries()
+		entries = append(entries, current...)
+	}
+	return entries
+}
+
+// StartHAR records the network traffic into a HAR file, sharing the recording of the trace
+func (t *Tracer) StartHAR(path string) error {
+	return t.record("StartHAR", []interface{}{path}, func() error {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.userHAR != "" {
+			return fmt.Errorf("already recording a HAR into %s", t.userHAR)
+		}
+		if t.harPath == "" && !t.sharing {
+			return t.Browser.StartHAR(path)
+		}
+		t.userHAR, t.userFrom = path, len(t.networkEntries())
+		return nil
+	})
+}
+
+// StopHAR writes the HAR started through StartHAR, the network log of the trace goes on
+func (t *Tracer) StopHAR() error {
+	return t.record("StopHAR", nil, func() error {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.stopHAR()
+	})
+}
+
+func (t *Tracer) stopHAR() error {
+	switch {
+	case t.userHAR != "":
+		entries := t.networkEntries()
+		path := t.userHAR
+		t.userHAR = ""
+		return newHAR(entries[min(t.userFrom, len(entries)):]).WriteFile(path)
+	case t.sharing:
+		// The browser's own recording ends, the trace goes on with one of its own
+		t.network = t.networkEntries()
+		t.sharing = false
+		err := t.Browser.StopHAR()
+		t.startNetwork()
+		return err
+	case t.harPath != "":
+		return nil
+	}
+	return t.Browser.StopHAR()
+}
+
+// record runs the action as a step of the trace, named after the Browser method it performs.
+// The lock is not held while the action runs, actions like WaitForPage run other traced ones.
+func (t *Tracer) record(name string, params []interface{}, action func() error) error {
+	step := TraceAction{Name: name, Start: time.Now()}
+	for _, param := range params {
+		step.Params = append(step.Params, fmt.Sprintf("%v", param))
+	}
+	// Reserving the place keeps the actions in the order they started
+	t.mu.Lock()
+	index := len(t.trace.Actions)
+	t.trace.Actions = append(t.trace.Actions, step)
+	t.mu.Unlock()
+
+	before, _ := t.snapshot()
+	err := action()
+	step.End = time.Now()
+	if err != nil {
+		step.Error = err.Error()
+	}
+	after, url := t.snapshot()
+	shot, shotErr := t.Browser.Screenshot()
+	messages, pageErrors := t.Browser.ConsoleMessages(), t.Browser.PageErrors()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if before != "" {
+		step.Before = fmt.Sprintf("snapshots/%03d-before.html", index+1)
+		t.files[step.Before] = []byte(before)
+	}
+	if after != "" {
+		step.After, step.URL = fmt.Sprintf("snapshots/%03d-after.html", index+1), url
+		t.files[step.After] = []byte(after)
+	}
+	if shotErr == nil {
+		step.Screenshot = fmt.Sprintf("screenshots/%03d.png", index+1)
+		t.files[step.Screenshot] = shot
+	}
+	for _, msg := range messages[min(t.console, len(messages)):] {
+		step.Console = append(step.Console, traceLog{Level: msg.Level, Text: msg.Text, Location: traceLocation(msg.Location)})
+	}
+	for _, pageErr := range pageErrors[min(t.errors, len(pageErrors)):] {
+		step.PageErrors = append(step.PageErrors, traceLog{Level: ConsoleError, Text: pageErr.Message, Location: traceLocation(pageErr.Location)})
+	}
+	t.console, t.errors = max(t.console, len(messages)), max(t.errors, len(pageErrors))
+
+	t.trace.Actions[index] = step
+	return err
+}
+
+// snapshot returns the DOM of the current page with the page's URL.
+// Both are empty when there is no page to take it from.
+func (t *Tracer) snapshot() (string, string) {
+	value, err := t.Browser.Evaluate(`() => ({url: location.href, html: "<!DOCTYPE html>" + document.documentElement.outerHTML})`)
+	page, ok := value.(map[string]interface{})
+	if err != nil || !ok {
+		return "", ""
+	}
+	html, _ := page["html"].(string)
+	url, _ := page["url"].(string)
+	return html, url
+}
+
+func traceLocation(location SourceLocation) string {
+	if location.URL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", location.URL, location.Line+1, location.Column+1)
+}
+
+// Save stops tracing and writes the trace archive to path
+func (t *Tracer) Save(path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trace.End = time.Now()
+
+	if t.harPath != "" || t.sharing || len(t.network) > 0 {
+		har, err := json.MarshalIndent(newHAR(t.networkEntries()), "", "  ")
+		if err == nil {
+			t.files[traceNetworkFile] = har
+			t.trace.Network = traceNetworkFile
+		} else {
+			log.Println("Trace has no network log:", err)
+		}
+	}
+	// A recording the tracer shares is left to its owner
+	if t.harPath != "" {
+		t.Browser.StopHAR()
+		os.Remove(t.harPath)
+		t.harPath = ""
+	}
+	t.network, t.sharing = nil, false
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to save trace: %w", err)
+	}
+	if err := t.writeArchive(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save trace: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to save trace: %w", err)
+	}
+	return nil
+}
+
+func (t *Tracer) writeArchive(file *os.File) error {
+	archive := zip.NewWriter(file)
+	index, err := archive.Create(traceIndexFile)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(index).Encode(t.trace); err != nil {
+		return err
+	}
+	for name, data := range t.files {
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// Close saves the trace when the browser was traced from the config, then closes the browser
+func (t *Tracer) Close() error {
+	return t.CloseContext(context.Background())
+}
+
+// CloseContext saves the trace when the browser was traced from the config, then closes the browser
+func (t *Tracer) CloseContext(ctx context.Context) error {
+	// Like the browser, closing writes a HAR that was started and not stopped
+	var harErr, saveErr error
+	t.mu.Lock()
+	if t.userHAR != "" {
+		harErr = t.stopHAR()
+	}
+	t.mu.Unlock()
+	if t.traceDir != "" {
+		saveErr = t.saveToDir(t.traceDir)
+	}
+	closeErr := t.Browser.CloseContext(ctx)
+	// Without a saved trace the browser wrote the network log into the temporary file while closing
+	t.mu.Lock()
+	if t.harPath != "" {
+		os.Remove(t.harPath)
+		t.harPath = ""
+	}
+	t.mu.Unlock()
+	return errors.Join(closeErr, harErr, saveErr)
+}
+
+// saveToDir saves the trace under a new name in the directory
+func (t *Tracer) saveToDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to save trace: %w", err)
+	}
+	file, err := os.CreateTemp(dir, fmt.Sprintf("trace-%s-*.zip", t.trace.Start.Format("20060102-150405")))
+	if err != nil {
+		return fmt.Errorf("failed to save trace: %w", err)
+	}
+	file.Close()
+	if err := t.Save(file.Name()); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	log.Println("Saved trace:", file.Name())
+	return nil
+}
+
+// OpenURL navigates the current page to the URL
+func (t *Tracer) OpenURL(url string) error {
+	return t.record("OpenURL", []interface{}{url}, func() error { return t.Browser.OpenURL(url) })
+}
+
+// OpenURLContext navigates the current page to the URL, giving up when ctx is done
+func (t *Tracer) OpenURLContext(ctx context.Context, url string) error {
+	return t.record("OpenURL", []interface{}{url}, func() error { return t.Browser.OpenURLContext(ctx, url) })
+}
+
+// NewPage opens a blank page and makes it current
+func (t *Tracer) NewPage() (PageID, error) {
+	var page PageID
+	err := t.record("NewPage", nil, func() (err error) {
+		page, err = t.Browser.NewPage()
+		return err
+	})
+	return page, err
+}
+
+// SwitchTo makes the page current
+func (t *Tracer) SwitchTo(page PageID) error {
+	return t.record("SwitchTo", []interface{}{page}, func() error { return t.Browser.SwitchTo(page) })
+}
+
+// ClosePage closes the current page
+func (t *Tracer) ClosePage() error {
+	return t.record("ClosePage", nil, t.Browser.ClosePage)
+}
+
+// WaitForPage runs the action and waits for the page it opens, the action's own calls are traced too
+func (t *Tracer) WaitForPage(action func() error) (PageID, error) {
+	var page PageID
+	err := t.record("WaitForPage", nil, func() (err error) {
+		page, err = t.Browser.WaitForPage(action)
+		return err
+	})
+	return page, err
+}
+
+// SetWindowRect moves and resizes the current window
+func (t *Tracer) SetWindowRect(rect WindowRect) error {
+	return t.record("SetWindowRect", []interface{}{rect}, func() error { return t.Browser.SetWindowRect(rect) })
+}
+
+// MaximizeWindow maximizes the current window
+func (t *Tracer) MaximizeWindow() error {
+	return t.record("MaximizeWindow", nil, t.Browser.MaximizeWindow)
+}
+
+// MinimizeWindow minimizes the current window
+func (t *Tracer) MinimizeWindow() error {
+	return t.record("MinimizeWindow", nil, t.Browser.MinimizeWindow)
+}
+
+// Emulate makes the pages of the browser context look like the device
+func (t *Tracer) Emulate(device Device) error {
+	return t.record("Emulate", []interface{}{device}, func() error { return t.Browser.Emulate(device) })
+}
+
+// SetGeolocation overrides the position pages get, nil removes the override
+func (t *Tracer) SetGeolocation(location *Geolocation) error {
+	params := []interface{}{}
+	if location != nil {
+		params = append(params, *location)
+	}
+	return t.record("SetGeolocation", params, func() error { return t.Browser.SetGeolocation(location) })
+}
+
+// SetTimezone overrides the timezone of the pages
+func (t *Tracer) SetTimezone(timezone string) error {
+	return t.record("SetTimezone", []interface{}{timezone}, func() error { return t.Browser.SetTimezone(timezone) })
+}
+
+// SetLocale overrides the locale of the pages
+func (t *Tracer) SetLocale(locale string) error {
+	return t.record("SetLocale", []interface{}{locale}, func() error { return t.Browser.SetLocale(locale) })
+}
+
+// EmulateMedia overrides the CSS media features of the pages
+func (t *Tracer) EmulateMedia(features MediaFeatures) error {
+	return t.record("EmulateMedia", []interface{}{features}, func() error { return t.Browser.EmulateMedia(features) })
+}
+
+// GrantPermissions grants the permissions to the origin
+func (t *Tracer) GrantPermissions(origin string, permissions ...Permission) error {
+	return t.record("GrantPermissions", []interface{}{origin, permissions}, func() error {
+		return t.Browser.GrantPermissions(origin, permissions...)
+	})
+}
+
+// DenyPermissions denies the permissions to the origin
+func (t *Tracer) DenyPermissions(origin string, permissions ...Permission) error {
+	return t.record("DenyPermissions", []interface{}{origin, permissions}, func() error {
+		return t.Browser.DenyPermissions(origin, permissions...)
+	})
+}
+
+// ResetPermissions removes all granted and denied permissions
+func (t *Tracer) ResetPermissions() error {
+	return t.record("ResetPermissions", nil, t.Browser.ResetPermissions)
+}
+
+// Frame switches into the frame matching the selector
+func (t *Tracer) Frame(selector string) error {
+	return t.record("Frame", []interface{}{selector}, func() error { return t.Browser.Frame(selector) })
+}
+
+// ParentFrame switches to the parent of the current frame
+func (t *Tracer) ParentFrame() error {
+	return t.record("ParentFrame", nil, t.Browser.ParentFrame)
+}
+
+// MainFrame switches back to the top level document
+func (t *Tracer) MainFrame() error {
+	return t.record("MainFrame", nil, t.Browser.MainFrame)
+}
+
+// Find returns the first element matching the selector, its actions are traced too
+func (t *Tracer) Find(selector string) (Element, error) {
+	return t.FindContext(context.Background(), selector)
+}
+
+// FindContext returns the first element matching the selector, giving up when ctx is done
+func (t *Tracer) FindContext(ctx context.Context, selector string) (Element, error) {
+	var el Element
+	err := t.record("Find", []interface{}{selector}, func() (err error) {
+		el, err = t.Browser.FindContext(ctx, selector)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tracedElement{Element: el, tracer: t, selector: selector}, nil
+}
+
+// FindAll returns all elements matching the selector, their actions are traced too
+func (t *Tracer) FindAll(selector string) ([]Element, error) {
+	return t.FindAllContext(context.Background(), selector)
+}
+
+// FindAllContext returns all elements matching the selector, giving up when ctx is done
+func (t *Tracer) FindAllContext(ctx context.Context, selector string) ([]Element, error) {
+	var els []Element
+	err := t.record("FindAll", []interface{}{selector}, func() (err error) {
+		els, err = t.Browser.FindAllContext(ctx, selector)
+		return err
+	})
+	for i, el := range els {
+		els[i] = &tracedElement{Element: el, tracer: t, selector: fmt.Sprintf("%s [%d]", selector, i)}
+	}
+	return els, err
+}
+
+// WaitLoad waits until the current page reaches the load state
+func (t *Tracer) WaitLoad(state LoadState) error {
+	return t.record("WaitLoad", []interface{}{state}, func() error { return t.Browser.WaitLoad(state) })
+}
+
+// WaitFor waits until the element matching the selector meets the condition
+func (t *Tracer) WaitFor(selector string, condition Condition) error {
+	return t.record("WaitFor", []interface{}{selector}, func() error { return t.Browser.WaitFor(selector, condition) })
+}
+
+// WaitForDownload runs the action and waits for the download it starts, the action's own calls are traced too
+func (t *Tracer) WaitForDownload(action func() error) (Download, error) {
+	var download Download
+	err := t.record("WaitForDownload", nil, func() (err error) {
+		download, err = t.Browser.WaitForDownload(action)
+		return err
+	})
+	return download, err
+}
+
+// Route handles the requests matching the route
+func (t *Tracer) Route(match RouteMatch, handler RouteHandler) error {
+	return t.record("Route", []interface{}{match}, func() error { return t.Browser.Route(match, handler) })
+}
+
+// Unroute removes the routes added with the match
+func (t *Tracer) Unroute(match RouteMatch) error {
+	return t.record("Unroute", []interface{}{match}, func() error { return t.Browser.Unroute(match) })
+}
+
+// RouteFromHAR answers the requests recorded in the HAR file with their recorded responses
+func (t *Tracer) RouteFromHAR(path string) error {
+	return t.record("RouteFromHAR", []interface{}{path}, func() error { return t.Browser.RouteFromHAR(path) })
+}
+
+// ThrottleNetwork emulates the network conditions
+func (t *Tracer) ThrottleNetwork(conditions NetworkConditions) error {
+	return t.record("ThrottleNetwork", []interface{}{conditions}, func() error { return t.Browser.ThrottleNetwork(conditions) })
+}
+
+// ThrottleCPU slows down the CPU by the rate
+func (t *Tracer) ThrottleCPU(rate float64) error {
+	return t.record("ThrottleCPU", []interface{}{rate}, func() error { return t.Browser.ThrottleCPU(rate) })
+}
+
+// Perform runs the input action sequence
+func (t *Tracer) Perform(actions *Actions) error {
+	return t.record("Perform", nil, func() error { return t.Browser.Perform(actions) })
+}
+
+// ReleaseActions releases all keys and mouse buttons held by earlier actions
+func (t *Tracer) ReleaseActions() error {
+	return t.record("ReleaseActions", nil, t.Browser.ReleaseActions)
+}
+
+// Evaluate runs the script in the current page and returns its result
+func (t *Tracer) Evaluate(script string, args ...interface{}) (interface{}, error) {
+	var value interface{}
+	err := t.record("Evaluate", append([]interface{}{script}, args...), func() (err error) {
+		value, err = t.Browser.Evaluate(script, args...)
+		return err
+	})
+	return value, err
+}
+
+// EvaluateAsync runs the script with a completion callback and returns the value the callback is called with
+func (t *Tracer) EvaluateAsync(script string, args ...interface{}) (interface{}, error) {
+	var value interface{}
+	err := t.record("EvaluateAsync", append([]interface{}{script}, args...), func() (err error) {
+		value, err = t.Browser.EvaluateAsync(script, args...)
+		return err
+	})
+	return value, err
+}
+
+// SetCookies adds the cookies to the browser context
+func (t *Tracer) SetCookies(cookies ...Cookie) error {
+	params := make([]interface{}, len(cookies))
+	for i, cookie := range cookies {
+		params[i] = cookie.Name
+	}
+	return t.record("SetCookies", params, func() error { return t.Browser.SetCookies(cookies...) })
+}
+
+// DeleteCookies deletes the cookies with the names
+func (t *Tracer) DeleteCookies(names ...string) error {
+	params := make([]interface{}, len(names))
+	for i, name := range names {
+		params[i] = name
+	}
+	return t.record("DeleteCookies", params, func() error { return t.Browser.DeleteCookies(names...) })
+}
+
+// SetStorageItem stores the value under the key in the storage area of the current page
+func (t *Tracer) SetStorageItem(area StorageArea, key, value string) error {
+	return t.record("SetStorageItem", []interface{}{area, key, value}, func() error {
+		return t.Browser.SetStorageItem(area, key, value)
+	})
+}
+
+// ClearStorage removes every item from the storage area of the current page
+func (t *Tracer) ClearStorage(area StorageArea) error {
+	return t.record("ClearStorage", []interface{}{area}, func() error { return t.Browser.ClearStorage(area) })
+}
+
+// SetStorageState restores the cookies and storage of the state
+func (t *Tracer) SetStorageState(state *StorageState) error {
+	return t.record("SetStorageState", nil, func() error { return t.Browser.SetStorageState(state) })
+}
+
+// LoadStorageState restores the cookies and storage saved in the file
+func (t *Tracer) LoadStorageState(path string) error {
+	return t.record("LoadStorageState", []interface{}{path}, func() error { return t.Browser.LoadStorageState(path) })
+}
+
+// tracedElement records the actions of an element found through a Tracer
+type tracedElement struct {
+	Element
+	tracer   *Tracer
+	selector string
+}
+
+// Unwrap returns the browser's element, which the browser methods taking elements need
+func (e *tracedElement) Unwrap() Element {
+	return e.Element
+}
+
+func (e *tracedElement) Click() error {
+	return e.tracer.record("Click", []interface{}{e.selector}, e.Element.Click)
+}
+
+// Type records only the length of the text, which may be a password
+func (e *tracedElement) Type(text string) error {
+	length := fmt.Sprintf("%d characters", utf8.RuneCountInString(text))
+	return e.tracer.record("Type", []interface{}{e.selector, length}, func() error { return e.Element.Type(text) })
+}
+
+func (e *tracedElement) SetFiles(paths ...string) error {
+	return e.tracer.record("SetFiles", []interface{}{e.selector, paths}, func() error { return e.Element.SetFiles(paths...) })
+}
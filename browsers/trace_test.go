@@ -0,0 +1,194 @@
+package browsers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// fakeBrowser is a launched browser without pages that records a HAR, the rest of Browser panics
+type fakeBrowser struct {
+	Browser
+	har      harRecorder
+	closeErr error
+	typed    string
+}
+
+func (f *fakeBrowser) ConsoleMessages() []ConsoleMessage { return nil }
+func (f *fakeBrowser) PageErrors() []PageError           { return nil }
+func (f *fakeBrowser) Screenshot() ([]byte, error)       { return nil, ErrInvalidSessionID }
+
+func (f *fakeBrowser) Evaluate(script string, args ...interface{}) (interface{}, error) {
+	return nil, ErrInvalidSessionID
+}
+
+func (f *fakeBrowser) Find(selector string) (Element, error) {
+	return f.FindContext(context.Background(), selector)
+}
+
+func (f *fakeBrowser) FindContext(ctx context.Context, selector string) (Element, error) {
+	return &fakeElement{browser: f}, nil
+}
+
+func (f *fakeBrowser) StartHAR(path string) error { return f.har.start(path) }
+
+func (f *fakeBrowser) StopHAR() error {
+	har, path := f.har.stop()
+	if path == "" {
+		return nil
+	}
+	return har.WriteFile(path)
+}
+
+func (f *fakeBrowser) CloseContext(ctx context.Context) error {
+	return errors.Join(f.StopHAR(), f.closeErr)
+}
+
+func (f *fakeBrowser) harEntries() ([]*HAREntry, bool) { return f.har.snapshot() }
+
+// request records a finished request to the URL
+func (f *fakeBrowser) request(url string) {
+	f.har.request(url, time.Now(), 0, harRequest("GET", url, nil, ""))
+	f.har.finish(url, 0, HARContent{}, 0)
+}
+
+type fakeElement struct {
+	Element
+	browser *fakeBrowser
+}
+
+func (e *fakeElement) Type(text string) error {
+	e.browser.typed += text
+	return nil
+}
+
+func TestUnwrapElement(t *testing.T) {
+	el := &webDriverElement{el: &webdriver.Element{ID: "el-1"}}
+	traced := &tracedElement{Element: &tracedElement{Element: el}}
+	if got := unwrapElement(traced); got != el {
+		t.Errorf("unwrapElement() = %v, want the browser's element", got)
+	}
+	if got := unwrapElement(el); got != el {
+		t.Errorf("unwrapElement() = %v, want the element itself", got)
+	}
+
+	sequences, err := webDriverActions(NewActions().MoveTo(traced))
+	if err != nil {
+		t.Fatalf("webDriverActions() error = %v", err)
+	}
+	if origin := sequences[1].Actions[0].Origin; origin != el.el {
+		t.Errorf("pointer origin = %v, want the web element", origin)
+	}
+	if args := webDriverArgs([]interface{}{traced, "text"}); args[0] != el.el || args[1] != "text" {
+		t.Errorf("webDriverArgs() = %v, want the web element and the text", args)
+	}
+}
+
+func TestTracerRedactsTypedText(t *testing.T) {
+	browser := &fakeBrowser{}
+	tracer := NewTracer(browser)
+	defer tracer.Close()
+	el, err := tracer.Find("#password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := el.Type("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if browser.typed != "hunter2" {
+		t.Errorf("element got %q, want the text", browser.typed)
+	}
+	action := tracer.trace.Actions[len(tracer.trace.Actions)-1]
+	if action.Name != "Type" || len(action.Params) != 2 || action.Params[0] != "#password" || action.Params[1] != "7 characters" {
+		t.Errorf("traced %s %v, want Type with the selector and the text length", action.Name, action.Params)
+	}
+}
+
+func TestTracerSharesHAR(t *testing.T) {
+	dir := t.TempDir()
+	for _, recordHAR := range []bool{false, true} {
+		browser := &fakeBrowser{}
+		configPath := dir + "/config.har"
+		if recordHAR {
+			if err := browser.StartHAR(configPath); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tracer := NewTracer(browser)
+		browser.request("https://example.com/1")
+
+		userPath := dir + "/user.har"
+		if err := tracer.StartHAR(userPath); err != nil {
+			t.Fatalf("StartHAR() error = %v", err)
+		}
+		if err := tracer.StartHAR(userPath); err == nil {
+			t.Error("second StartHAR() succeeded, want an error")
+		}
+		browser.request("https://example.com/2")
+		if err := tracer.StopHAR(); err != nil {
+			t.Fatalf("StopHAR() error = %v", err)
+		}
+		browser.request("https://example.com/3")
+		if recordHAR {
+			// Stopping again ends the recording of the config, the trace goes on without it
+			if err := tracer.StopHAR(); err != nil {
+				t.Fatalf("StopHAR() error = %v", err)
+			}
+			browser.request("https://example.com/4")
+		}
+
+		if got := harURLs(t, userPath); len(got) != 1 || got[0] != "https://example.com/2" {
+			t.Errorf("recordHar %v: user HAR = %v, want only the request made while recording", recordHAR, got)
+		}
+		if recordHAR {
+			if got := harURLs(t, configPath); len(got) != 3 {
+				t.Errorf("config HAR = %v, want the 3 requests made before it stopped", got)
+			}
+		}
+		want := 3
+		if recordHAR {
+			want = 4
+		}
+		if got := len(tracer.networkEntries()); got != want {
+			t.Errorf("recordHar %v: trace has %d requests, want %d", recordHAR, got, want)
+		}
+		if err := tracer.Save(dir + "/trace.zip"); err != nil {
+			t.Fatal(err)
+		}
+		if tracer.trace.Network != traceNetworkFile || len(tracer.files[traceNetworkFile]) == 0 {
+			t.Errorf("recordHar %v: trace has no network log", recordHAR)
+		}
+	}
+}
+
+func TestTracerCloseRemovesTemporaryHAR(t *testing.T) {
+	browser := &fakeBrowser{closeErr: errors.New("close failed")}
+	tracer := NewTracer(browser)
+	path := tracer.harPath
+	if path == "" {
+		t.Fatal("tracer records no network")
+	}
+	if err := tracer.Close(); !errors.Is(err, browser.closeErr) {
+		t.Errorf("Close() error = %v, want the close error", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("temporary HAR %s still exists", path)
+	}
+}
+
+func harURLs(t *testing.T, path string) []string {
+	t.Helper()
+	har, err := ReadHAR(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, entry := range har.Log.Entries {
+		urls = append(urls, entry.Request.URL)
+	}
+	return urls
+}
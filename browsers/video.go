@@ -0,0 +1,368 @@
+package browsers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// videoMaxSize caps the width and height of the recorded frames in pixels
+const videoMaxSize = 1280
+
+// videoFrameInterval is the frame interval of the videos, frames painted sooner after the previous one are dropped
+const videoFrameInterval = 40 * time.Millisecond
+
+// videoRecorder streams the screencast frames of every page into its video file as they arrive.
+// Frames arrive on event goroutines, so access is guarded.
+type videoRecorder struct {
+	mu      sync.Mutex
+	dir     string // empty while not recording
+	started time.Time
+	videos  []*pageVideo
+}
+
+// pageVideo is the recording of one page, its file is created with the first frame
+type pageVideo struct {
+	page    *rod.Page
+	pattern string
+	avi     *aviWriter
+	start   time.Time
+	err     error
+}
+
+func (r *videoRecorder) start(dir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dir != "" {
+		return fmt.Errorf("already recording videos into %s", r.dir)
+	}
+	r.dir, r.started, r.videos = dir, time.Now(), nil
+	return nil
+}
+
+// addPage starts the video of a page
+func (r *videoRecorder) addPage(page *rod.Page) *pageVideo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	video := &pageVideo{
+		page:    page,
+		pattern: fmt.Sprintf("video-%s-page%d-*.avi", r.started.Format("20060102-150405"), len(r.videos)+1),
+	}
+	r.videos = append(r.videos, video)
+	return video
+}
+
+// addFrame writes the frame into the video, repeating the previous frame until the time it was painted
+func (r *videoRecorder) addFrame(video *pageVideo, data []byte, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dir == "" || video.err != nil {
+		return
+	}
+	if video.avi == nil {
+		file, err := os.CreateTemp(r.dir, video.pattern)
+		if err != nil {
+			video.err = err
+			return
+		}
+		video.avi, video.start = newAVIWriter(file), at
+	}
+	frame := int(at.Sub(video.start) / videoFrameInterval)
+	if frame < video.avi.frames {
+		return
+	}
+	video.err = video.avi.repeat(frame)
+	if video.err == nil {
+		video.err = video.avi.write(data)
+	}
+}
+
+// stop ends recording and returns the videos, false when not recording
+func (r *videoRecorder) stop() ([]*pageVideo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recording, videos := r.dir != "", r.videos
+	r.dir, r.videos = "", nil
+	return videos, recording
+}
+
+// finishVideos completes the video files and returns their paths, or removes them unless keep is set.
+// The last frame of every video lasts until end.
+func finishVideos(videos []*pageVideo, end time.Time, keep bool) ([]string, error) {
+	var paths []string
+	var firstErr error
+	for _, video := range videos {
+		if video.avi == nil {
+			if video.err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("failed to save video: %w", video.err)
+			}
+			continue
+		}
+		name := video.avi.file.Name()
+		err := video.err
+		if err == nil && keep {
+			err = video.avi.repeat(int(end.Sub(video.start) / videoFrameInterval))
+			if err == nil {
+				err = video.avi.finish()
+			}
+		}
+		if closeErr := video.avi.file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil || !keep {
+			os.Remove(name)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to save video: %w", err)
+			}
+			continue
+		}
+		if keep {
+			paths = append(paths, name)
+		}
+	}
+	return paths, firstErr
+}
+
+// aviWriter writes a Motion JPEG AVI file frame by frame. Only the index is kept in memory,
+// the header is written with placeholder sizes and completed by finish.
+type aviWriter struct {
+	file     *os.File
+	offset   int64 // end of the data written so far
+	index    bytes.Buffer
+	frames   int
+	maxSize  int
+	width    int
+	height   int
+	writeErr error
+}
+
+// aviHeaderSize is the size of the header up to the first frame, aviMoviOffset is where the frame offsets count from
+const (
+	aviHeaderSize = 224
+	aviMoviOffset = 220
+)
+
+func newAVIWriter(file *os.File) *aviWriter {
+	w := &aviWriter{file: file, offset: aviHeaderSize}
+	_, w.writeErr = file.Write(w.header(0))
+	return w
+}
+
+// write appends a JPEG frame
+func (w *aviWriter) write(data []byte) error {
+	if config, err := jpeg.DecodeConfig(bytes.NewReader(data)); err == nil {
+		// Frames change size with the viewport, the video has the largest one
+		w.width, w.height = max(w.width, config.Width), max(w.height, config.Height)
+	}
+	return w.chunk(data)
+}
+
+// repeat shows the previous frame until the video has the number of frames.
+// An empty chunk is the AVI way of repeating a frame without storing it again.
+func (w *aviWriter) repeat(frames int) error {
+	for w.frames < frames {
+		if err := w.chunk(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *aviWriter) chunk(data []byte) error {
+	if w.writeErr != nil {
+		return w.writeErr
+	}
+	var flags uint32
+	if len(data) > 0 {
+		flags = 0x10 // key frame
+	}
+	w.index.WriteString("00dc")
+	binary.Write(&w.index, binary.LittleEndian, [3]uint32{flags, uint32(w.offset - aviMoviOffset), uint32(len(data))})
+
+	chunk := make([]byte, 8, 8+len(data)+1)
+	copy(chunk, "00dc")
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		// Chunks start at even offsets
+		chunk = append(chunk, 0)
+	}
+	if _, err := w.file.Write(chunk); err != nil {
+		w.writeErr = err
+		return err
+	}
+	w.offset += int64(len(chunk))
+	w.frames++
+	w.maxSize = max(w.maxSize, len(data))
+	return nil
+}
+
+// finish appends the index and rewrites the header with the final sizes
+func (w *aviWriter) finish() error {
+	if w.writeErr != nil {
+		return w.writeErr
+	}
+	var idx bytes.Buffer
+	idx.WriteString("idx1")
+	binary.Write(&idx, binary.LittleEndian, uint32(w.index.Len()))
+	idx.Write(w.index.Bytes())
+	if _, err := w.file.Write(idx.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.file.WriteAt(w.header(w.offset+int64(idx.Len())), 0)
+	return err
+}
+
+// header returns the AVI header for the frames written so far and the total file size
+func (w *aviWriter) header(size int64) []byte {
+	rate := uint32(time.Second / videoFrameInterval)
+	fields := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'}, uint32(max(size-8, 0)), [4]byte{'A', 'V', 'I', ' '},
+		[4]byte{'L', 'I', 'S', 'T'}, uint32(192), [4]byte{'h', 'd', 'r', 'l'},
+		// Main header
+		[4]byte{'a', 'v', 'i', 'h'}, uint32(56),
+		[14]uint32{
+			uint32(videoFrameInterval / time.Microsecond), uint32(w.maxSize) * rate, 0, 0x10, // has index
+			uint32(w.frames), 0, 1, uint32(w.maxSize), uint32(w.width), uint32(w.height),
+		},
+		[4]byte{'L', 'I', 'S', 'T'}, uint32(116), [4]byte{'s', 't', 'r', 'l'},
+		// Video stream header
+		[4]byte{'s', 't', 'r', 'h'}, uint32(56), [4]byte{'v', 'i', 'd', 's'}, [4]byte{'M', 'J', 'P', 'G'},
+		[10]uint32{0, 0, 0, 1, rate, 0, uint32(w.frames), uint32(w.maxSize), math.MaxUint32, 0},
+		[4]uint16{0, 0, uint16(w.width), uint16(w.height)},
+		// Video stream format
+		[4]byte{'s', 't', 'r', 'f'}, uint32(40),
+		uint32(40), int32(w.width), int32(w.height), uint16(1), uint16(24), [4]byte{'M', 'J', 'P', 'G'},
+		uint32(w.width * w.height * 3), [4]uint32{},
+		[4]byte{'L', 'I', 'S', 'T'}, uint32(max(w.offset-aviMoviOffset, 4)), [4]byte{'m', 'o', 'v', 'i'},
+	}
+	var header bytes.Buffer
+	for _, field := range fields {
+		binary.Write(&header, binary.LittleEndian, field)
+	}
+	return header.Bytes()
+}
+
+// StartVideo records a video of every page of the browser context, open and opened later.
+// The videos are streamed into dir as Motion JPEG AVI files at 25 frames per second, StopVideo or Close completes them.
+func (c *Chrome) StartVideo(dir string) error {
+	if c.Browser == nil {
+		return fmt.Errorf("browser not launched: %w", ErrInvalidSessionID)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create video directory: %w", err)
+	}
+	if err := c.video.start(dir); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopVideo = cancel
+	var mu sync.Mutex
+	recorded := map[proto.TargetTargetID]bool{}
+	err := c.watchPages(ctx, func(page *rod.Page) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if recorded[page.TargetID] {
+			return nil
+		}
+		recorded[page.TargetID] = true
+		return c.screencast(ctx, page)
+	})
+	if err != nil {
+		cancel()
+		c.stopVideo = nil
+		c.video.stop()
+		return fmt.Errorf("failed to record video: %w", err)
+	}
+	return nil
+}
+
+// screencast adds the frames Chrome paints for the page to its video until ctx is done
+func (c *Chrome) screencast(ctx context.Context, page *rod.Page) error {
+	video := c.video.addPage(page)
+	wait := page.Context(ctx).EachEvent(func(e *proto.PageScreencastFrame) {
+		at := time.Now()
+		if e.Metadata != nil && e.Metadata.Timestamp > 0 {
+			at = e.Metadata.Timestamp.Time()
+		}
+		c.video.addFrame(video, e.Data, at)
+		// Chrome sends the next frame only once this one is acknowledged
+		proto.PageScreencastFrameAck{SessionID: e.SessionID}.Call(page)
+	})
+	go wait()
+
+	quality, size := 80, videoMaxSize
+	err := proto.PageStartScreencast{
+		Format:    proto.PageStartScreencastFormatJpeg,
+		Quality:   &quality,
+		MaxWidth:  &size,
+		MaxHeight: &size,
+	}.Call(page)
+	return chromeError(err)
+}
+
+// StopVideo stops recording and, when keep is set, completes the videos and returns their paths,
+// otherwise it removes them. It does nothing when not recording.
+func (c *Chrome) StopVideo(keep bool) ([]string, error) {
+	if c.stopVideo != nil {
+		c.stopVideo()
+		c.stopVideo = nil
+	}
+	videos, recording := c.video.stop()
+	if !recording {
+		return nil, nil
+	}
+	end := time.Now()
+	for _, video := range videos {
+		// The page may be closed already, which ends its screencast too
+		proto.PageStopScreencast{}.Call(video.page)
+	}
+	return finishVideos(videos, end, keep)
+}
+
+// SetFailed marks whether the run failed, Close keeps the videos of failed runs only when retainVideoOnFailure is set
+func (c *Chrome) SetFailed(failed bool) {
+	c.failed = failed
+}
+
+// closeVideo stops recording on Close, keeping the videos unless only those of failed runs are retained
+func (c *Chrome) closeVideo() error {
+	keep := !c.Options.RetainVideoOnFailure || c.failed ||
+		(c.Options.FailOnPageError && c.CheckPageErrors() != nil)
+	paths, err := c.StopVideo(keep)
+	for _, path := range paths {
+		log.Println("Saved video:", path)
+	}
+	return err
+}
+
+// StartVideo is unsupported, WebDriver has no command to stream the painted frames
+func (b *webDriverBrowser) StartVideo(dir string) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	return fmt.Errorf("video recording needs the Chrome DevTools Protocol, which %s does not offer: %w", b.name, ErrUnsupported)
+}
+
+// StopVideo does nothing, the WebDriver browsers record no videos
+func (b *webDriverBrowser) StopVideo(keep bool) ([]string, error) {
+	return nil, nil
+}
+
+// SetFailed marks whether the run failed
+func (b *webDriverBrowser) SetFailed(failed bool) {
+	b.failed = failed
+}
@@ -0,0 +1,224 @@
+package browsers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"os"
+	"testing"
+	"time"
+)
+
+// testJPEG encodes a blank frame of the size
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// aviChunk is a chunk of an AVI file, lists hold the chunks inside them
+type aviChunk struct {
+	id     string
+	offset int
+	data   []byte
+	list   []aviChunk
+}
+
+func parseAVIChunks(t *testing.T, data []byte, offset int) []aviChunk {
+	t.Helper()
+	var chunks []aviChunk
+	for pos := 0; pos < len(data); {
+		if pos+8 > len(data) {
+			t.Fatalf("truncated chunk header at %d", offset+pos)
+		}
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if pos+8+size > len(data) {
+			t.Fatalf("chunk %s at %d has size %d past the end of its list", id, offset+pos, size)
+		}
+		chunk := aviChunk{id: id, offset: offset + pos, data: data[pos+8 : pos+8+size]}
+		if id == "RIFF" || id == "LIST" {
+			chunk.id = string(chunk.data[:4])
+			chunk.list = parseAVIChunks(t, chunk.data[4:], chunk.offset+12)
+		}
+		chunks = append(chunks, chunk)
+		pos += 8 + size + size%2
+	}
+	return chunks
+}
+
+func findAVIChunk(t *testing.T, chunks []aviChunk, path ...string) aviChunk {
+	t.Helper()
+	for _, chunk := range chunks {
+		if chunk.id != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return chunk
+		}
+		return findAVIChunk(t, chunk.list, path[1:]...)
+	}
+	t.Fatalf("no %s chunk", path[0])
+	return aviChunk{}
+}
+
+func TestAVIWriter(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "*.avi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// An odd sized frame checks the padding, the larger one the size in the header
+	small, large := testJPEG(t, 320, 200), testJPEG(t, 640, 480)
+	if len(small)%2 == 0 {
+		small = append(small, 0)
+	}
+	w := newAVIWriter(file)
+	if err := w.write(small); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.repeat(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.write(large); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.repeat(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := parseAVIChunks(t, data, 0)
+	if len(chunks) != 1 || chunks[0].id != "AVI " || chunks[0].offset != 0 {
+		t.Fatalf("file is not one RIFF AVI chunk: %+v", chunks)
+	}
+	riff := chunks[0].list
+
+	avih := findAVIChunk(t, riff, "hdrl", "avih").data
+	header := make([]uint32, len(avih)/4)
+	binary.Read(bytes.NewReader(avih), binary.LittleEndian, header)
+	if header[0] != 40000 || header[4] != 4 || header[6] != 1 || header[8] != 640 || header[9] != 480 {
+		t.Errorf("avih = %v, want 40000µs per frame, 4 frames, 1 stream, 640x480", header)
+	}
+	strh := findAVIChunk(t, riff, "hdrl", "strl", "strh").data
+	if string(strh[:8]) != "vidsMJPG" || binary.LittleEndian.Uint32(strh[24:]) != 25 || binary.LittleEndian.Uint32(strh[32:]) != 4 {
+		t.Errorf("strh = %q, want an MJPG video stream of 4 frames at 25 per second", strh)
+	}
+
+	movi := findAVIChunk(t, riff, "movi")
+	if movi.offset+8 != aviMoviOffset {
+		t.Errorf("movi list at %d, want its type at %d", movi.offset, aviMoviOffset)
+	}
+	wantSizes := []int{len(small), 0, 0, len(large)}
+	if len(movi.list) != len(wantSizes) {
+		t.Fatalf("movi has %d chunks, want %d", len(movi.list), len(wantSizes))
+	}
+	for i, chunk := range movi.list {
+		if chunk.id != "00dc" || len(chunk.data) != wantSizes[i] {
+			t.Errorf("frame %d = %s of %d bytes, want 00dc of %d", i, chunk.id, len(chunk.data), wantSizes[i])
+		}
+	}
+
+	idx := findAVIChunk(t, riff, "idx1").data
+	if len(idx) != 16*len(movi.list) {
+		t.Fatalf("idx1 has %d bytes, want 16 per frame", len(idx))
+	}
+	for i, chunk := range movi.list {
+		entry := idx[16*i:]
+		flags := binary.LittleEndian.Uint32(entry[4:])
+		offset := int(binary.LittleEndian.Uint32(entry[8:]))
+		size := int(binary.LittleEndian.Uint32(entry[12:]))
+		if string(entry[:4]) != "00dc" || aviMoviOffset+offset != chunk.offset || size != len(chunk.data) {
+			t.Errorf("index %d points at %d with size %d, want %d with size %d", i, aviMoviOffset+offset, size, chunk.offset, len(chunk.data))
+		}
+		if keyFrame := flags&0x10 != 0; keyFrame != (size > 0) {
+			t.Errorf("index %d key frame flag = %v for a frame of %d bytes", i, keyFrame, size)
+		}
+	}
+}
+
+func TestVideoRecorderFrameRate(t *testing.T) {
+	dir := t.TempDir()
+	var r videoRecorder
+	if err := r.start(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.start(dir); err == nil {
+		t.Error("second start succeeded, want an error")
+	}
+	video := r.addPage(nil)
+	frame := testJPEG(t, 100, 100)
+	start := time.Now()
+	// Frames painted within one frame interval of the previous frame are dropped
+	for _, ms := range []int{0, 10, 39, 45, 200, 210, 500} {
+		r.addFrame(video, frame, start.Add(time.Duration(ms)*time.Millisecond))
+	}
+	if video.err != nil {
+		t.Fatal(video.err)
+	}
+	if video.avi.frames != 13 {
+		t.Errorf("frames = %d, want 13 up to the one painted at 500ms", video.avi.frames)
+	}
+
+	videos, recording := r.stop()
+	if !recording || len(videos) != 1 {
+		t.Fatalf("stop() = %d videos, %v, want 1 while recording", len(videos), recording)
+	}
+	r.addFrame(video, frame, start.Add(time.Second))
+	paths, err := finishVideos(videos, start.Add(time.Second), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || video.avi.frames != 25 {
+		t.Errorf("finishVideos() = %v with %d frames, want one video of 25 frames lasting until the end", paths, video.avi.frames)
+	}
+	written := 0
+	for _, chunk := range findAVIChunk(t, parseAVIChunks(t, mustRead(t, paths[0]), 0)[0].list, "movi").list {
+		if len(chunk.data) > 0 {
+			written++
+		}
+	}
+	if written != 4 {
+		t.Errorf("video stores %d frames, want the 4 kept ones", written)
+	}
+}
+
+func TestFinishVideosDiscard(t *testing.T) {
+	var r videoRecorder
+	if err := r.start(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	video := r.addPage(nil)
+	r.addPage(nil) // a page that painted nothing has no file
+	r.addFrame(video, testJPEG(t, 10, 10), time.Now())
+	name := video.avi.file.Name()
+
+	videos, _ := r.stop()
+	paths, err := finishVideos(videos, time.Now(), false)
+	if err != nil || len(paths) != 0 {
+		t.Fatalf("finishVideos() = %v, %v, want no videos", paths, err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("discarded video %s still exists", name)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
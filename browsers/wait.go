@@ -0,0 +1,203 @@
+package browsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// WaitOptions controls how long and how often wait conditions are polled
+type WaitOptions struct {
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// DefaultWaitOptions are used for every field left zero in WaitOptions
+var DefaultWaitOptions = WaitOptions{
+	Timeout:  30 * time.Second,
+	Interval: 100 * time.Millisecond,
+}
+
+// withDefaults fills zero fields from DefaultWaitOptions
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultWaitOptions.Timeout
+	}
+	if o.Interval <= 0 {
+		o.Interval = DefaultWaitOptions.Interval
+	}
+	return o
+}
+
+// LoadState is a page lifecycle state that can be waited for
+type LoadState string
+
+// Supported page load states
+const (
+	LoadStateDOMContentLoaded LoadState = "domcontentloaded"
+	LoadStateLoad             LoadState = "load"
+	LoadStateNetworkIdle      LoadState = "networkidle"
+)
+
+// networkIdleTime is how long no new requests may start before the network counts as idle
+const networkIdleTime = 500 * time.Millisecond
+
+// Condition is an element state that can be waited for with Browser.WaitFor
+type Condition struct {
+	Name  string
+	Check func(elements []Element) (bool, error)
+}
+
+// Attached is satisfied once at least one element matches the selector
+var Attached = Condition{
+	Name: "attached",
+	Check: func(elements []Element) (bool, error) {
+		return len(elements) > 0, nil
+	},
+}
+
+// Visible is satisfied once the first matching element is visible
+var Visible = Condition{
+	Name: "visible",
+	Check: func(elements []Element) (bool, error) {
+		if len(elements) == 0 {
+			return false, nil
+		}
+		return elements[0].Visible()
+	},
+}
+
+// Hidden is satisfied once no matching element is visible
+var Hidden = Condition{
+	Name: "hidden",
+	Check: func(elements []Element) (bool, error) {
+		for _, el := range elements {
+			visible, err := el.Visible()
+			if err != nil || visible {
+				return false, err
+			}
+		}
+		return true, nil
+	},
+}
+
+// Enabled is satisfied once the first matching element is enabled
+var Enabled = Condition{
+	Name: "enabled",
+	Check: func(elements []Element) (bool, error) {
+		if len(elements) == 0 {
+			return false, nil
+		}
+		return elements[0].Enabled()
+	},
+}
+
+// TextMatches is satisfied once the text of the first matching element matches the pattern
+func TextMatches(pattern *regexp.Regexp) Condition {
+	return Condition{
+		Name: fmt.Sprintf("text matching %q", pattern),
+		Check: func(elements []Element) (bool, error) {
+			if len(elements) == 0 {
+				return false, nil
+			}
+			text, err := elements[0].Text()
+			return err == nil && pattern.MatchString(text), err
+		},
+	}
+}
+
+// CountEquals is satisfied once exactly n elements match the selector
+func CountEquals(n int) Condition {
+	return Condition{
+		Name: fmt.Sprintf("count %d", n),
+		Check: func(elements []Element) (bool, error) {
+			return len(elements) == n, nil
+		},
+	}
+}
+
+// poll calls check every interval until it reports true, fails with a non-retryable error, or the timeout or ctx expires.
+// Missing and stale elements are treated as not yet satisfied.
+func poll(ctx context.Context, opts WaitOptions, check func() (bool, error)) error {
+	opts = opts.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		ok, err := check()
+		switch {
+		case err == nil && ok:
+			return nil
+		case err != nil && !retryable(err):
+			return err
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ctx.Err()
+			}
+			if lastErr != nil {
+				return fmt.Errorf("%w: %w", ErrTimeout, lastErr)
+			}
+			return ErrTimeout
+		case <-ticker.C:
+		}
+	}
+}
+
+// retryable reports whether a failed check may succeed on a later poll
+func retryable(err error) bool {
+	return errors.Is(err, ErrNoSuchElement) ||
+		errors.Is(err, ErrStaleElementReference) ||
+		errors.Is(err, ErrElementNotInteractable) ||
+		errors.Is(err, ErrElementClickIntercepted)
+}
+
+// waitFor polls find until the condition holds for the matched elements
+func waitFor(ctx context.Context, opts WaitOptions, selector string, condition Condition, find func(string) ([]Element, error)) error {
+	err := poll(ctx, opts, func() (bool, error) {
+		elements, err := find(selector)
+		if err != nil {
+			return false, err
+		}
+		return condition.Check(elements)
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for %q to be %s: %w", selector, condition.Name, err)
+	}
+	return nil
+}
+
+// waitFind polls find until it returns an element
+func waitFind(ctx context.Context, opts WaitOptions, selector string, find func(string) (Element, error)) (Element, error) {
+	var el Element
+	err := poll(ctx, opts, func() (bool, error) {
+		var err error
+		el, err = find(selector)
+		return err == nil, err
+	})
+	return el, err
+}
+
+// waitActionable waits until the element is visible and enabled so it can receive input
+func waitActionable(ctx context.Context, opts WaitOptions, el Element) error {
+	err := poll(ctx, opts, func() (bool, error) {
+		visible, err := el.Visible()
+		if err != nil || !visible {
+			return false, err
+		}
+		return el.Enabled()
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for element to be actionable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package browsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/valdemart123/go-owl/config"
+	"github.com/valdemart123/go-owl/webdriver"
+)
+
+// webDriverBrowser implements Browser on top of a W3C WebDriver session.
+// Firefox and WebKit embed it and only differ in how the driver is started.
+type webDriverBrowser struct {
+	name    string
+	cmd     *exec.Cmd
+	client  *webdriver.Client
+	session *webdriver.Session
+	wait    WaitOptions
+	slowMo  time.Duration
+	dialogs dialogState
+
+	downloadDir     string
+	ownsDownloadDir bool // downloadDir is temporary and removed on close
+	visited         originSet
+
+	bidi        *webdriver.BiDi // nil when the driver offers no BiDi connection
+	routes      routeState
+	intercept   string // BiDi intercept pausing requests for the routes
+	routeEvents bool
+	har         harRecorder
+	harEvents   bool
+
+	console         consoleState
+	failOnPageError bool
+
+	device      Device // emulated from launch, its viewport is the only part changeable later
+	permissions map[permissionKey]bool
+	failed      bool
+}
+
+// driverPort returns the port of the driver URL, which the driver process is started on
+func driverPort(driverURL string) string {
+	u, err := url.Parse(driverURL)
+	if err != nil || u.Port() == "" {
+		return "4444"
+	}
+	return u.Port()
+}
+
+// waitDriverReady polls the driver status endpoint until it accepts new sessions
+func (b *webDriverBrowser) waitDriverReady(ctx context.Context) error {
+	opts := b.wait.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	return b.client.WaitReady(ctx, opts.Interval)
+}
+
+// createSession opens a new WebDriver session with the given capabilities.
+// Prompts are left open by the driver and closed by the dialog handler.
+func (b *webDriverBrowser) createSession(ctx context.Context, caps webdriver.Capabilities) error {
+	caps.UnhandledPromptBehavior = "ignore"
+	session, err := b.client.NewSession(ctx, caps)
+	if err != nil {
+		return err
+	}
+	session.PromptHandler = b.handlePrompt
+	b.session = session
+
+	if caps.WebSocketURL {
+		bidi, err := session.ConnectBiDi(ctx)
+		if err != nil {
+			// Only network and console features depend on BiDi, everything else works without it
+			log.Printf("BiDi is unavailable in %s: %v\n", b.name, err)
+			return nil
+		}
+		b.bidi = bidi
+		if err := b.captureConsole(ctx); err != nil {
+			log.Printf("Console messages of %s are not collected: %v\n", b.name, err)
+		}
+	}
+	return nil
+}
+
+// newContext sets up isolated with another session against the same driver, kept apart from the others by the browser
+func (b *webDriverBrowser) newContext(ctx context.Context, caps webdriver.Capabilities, isolated *webDriverBrowser) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	isolated.name, isolated.client, isolated.wait, isolated.slowMo = b.name, b.client, b.wait, b.slowMo
+	isolated.device = b.device
+	return isolated.createSession(ctx, caps)
+}
+
+// applyOptions applies the launch options that take effect once the session exists
+func (b *webDriverBrowser) applyOptions(ctx context.Context, opts config.LaunchConfig) error {
+	b.failOnPageError = opts.FailOnPageError
+	if opts.VideoDir != "" {
+		log.Printf("Launch option %q is not supported by %s, ignoring it.\n", "videoDir", b.name)
+	}
+	if err := b.setWindowSize(ctx, opts.WindowSize); err != nil {
+		return fmt.Errorf("failed to set window size: %w", err)
+	}
+	if err := b.setViewport(ctx, b.device.Viewport); err != nil {
+		return fmt.Errorf("failed to emulate device: %w", err)
+	}
+	if opts.StorageState != "" {
+		if err := b.LoadStorageState(opts.StorageState); err != nil {
+			return fmt.Errorf("failed to restore storage state: %w", err)
+		}
+	}
+	if opts.ReplayHAR != "" {
+		if err := b.RouteFromHAR(opts.ReplayHAR); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freePort returns a local TCP port that is currently unused
+func freePort() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+// setWindowSize resizes the browser window when a size is configured
+func (b *webDriverBrowser) setWindowSize(ctx context.Context, size config.WindowSize) error {
+	if size.Width <= 0 || size.Height <= 0 {
+		return nil
+	}
+	_, err := b.session.SetWindowRect(ctx, webdriver.Rect{Width: float64(size.Width), Height: float64(size.Height)})
+	return err
+}
+
+// delay pauses before a navigation or element action when slow motion is configured
+func (b *webDriverBrowser) delay(ctx context.Context) {
+	if b.slowMo <= 0 {
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(b.slowMo):
+	}
+}
+
+// checkSession reports an error if the browser has not been launched
+func (b *webDriverBrowser) checkSession() error {
+	if b.session == nil {
+		return fmt.Errorf("no active session, start %s first: %w", b.name, ErrInvalidSessionID)
+	}
+	return nil
+}
+
+// OpenURL navigates to the given URL in the active session
+func (b *webDriverBrowser) OpenURL(url string) error {
+	return b.OpenURLContext(context.Background(), url)
+}
+
+// OpenURLContext navigates to the given URL in the active session, giving up when ctx is done
+func (b *webDriverBrowser) OpenURLContext(ctx context.Context, url string) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+	b.delay(ctx)
+	if err := b.session.Navigate(ctx, url); err != nil {
+		return fmt.Errorf("failed to open URL: %w", err)
+	}
+	// Remember where the page ended up for StorageState, after any redirects
+	if current, err := b.session.CurrentURL(ctx); err == nil {
+		b.visited.add(current)
+	}
+
+	log.Printf("Opened URL in %s: %s\n", b.name, url)
+	return nil
+}
+
+// SetWaitOptions sets the timeout and polling interval used by waits and auto-waiting actions
+func (b *webDriverBrowser) SetWaitOptions(opts WaitOptions) {
+	b.wait = opts
+}
+
+// WaitLoad waits until the current page reaches the load state
+func (b *webDriverBrowser) WaitLoad(state LoadState) error {
+	return b.waitLoad(context.Background(), state)
+}
+
+func (b *webDriverBrowser) waitLoad(ctx context.Context, state LoadState) error {
+	if err := b.checkSession(); err != nil {
+		return err
+	}
+
+	var resources int
+	var idleSince time.Time
+	err := poll(ctx, b.wait, func() (bool, error) {
+		var page struct {
+			ReadyState string `json:"readyState"`
+			Resources  int    `json:"resources"`
+		}
+		script := `return {readyState: document.readyState, resources: performance.getEntriesByType("resource").length}`
+		if err := b.session.ExecuteScript(ctx, script, nil, &page); err != nil {
+			return false, err
+		}
+
+		switch state {
+		case LoadStateDOMContentLoaded:
+			return page.ReadyState != "loading", nil
+		case LoadStateLoad:
+			return page.ReadyState == "complete", nil
+		case LoadStateNetworkIdle:
+			if page.ReadyState != "complete" || page.Resources != resources || idleSince.IsZero() {
+				resources = page.Resources
+				idleSince = time.Now()
+				return false, nil
+			}
+			return time.Since(idleSince) >= networkIdleTime, nil
+		default:
+			return false, fmt.Errorf("unknown load state %q: %w", state, ErrInvalidArgument)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for %s: %w", state, err)
+	}
+	return nil
+}
+
+// WaitFor waits until the elements matching the CSS selector satisfy the condition
+func (b *webDriverBrowser) WaitFor(selector string, condition Condition) error {
+	ctx := context.Background()
+	return waitFor(ctx, b.wait, selector, condition, func(selector string) ([]Element, error) {
+		return b.FindAllContext(ctx, selector)
+	})
+}
+
+// Find waits for the first element matching the CSS selector in the active session
+func (b *webDriverBrowser) Find(selector string) (Element, error) {
+	return b.FindContext(context.Background(), selector)
+}
+
+// FindContext waits for the first element matching the CSS selector, giving up when ctx is done.
+// The returned element performs its actions under ctx.
+func (b *webDriverBrowser) FindContext(ctx context.Context, selector string) (Element, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	el, err := waitFind(ctx, b.wait, selector, func(selector string) (Element, error) {
+		el, err := webDriverFind(ctx, b.session, selector)
+		if err != nil {
+			return nil, err
+		}
+		return &webDriverElement{el: el, browser: b, ctx: ctx}, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find element %q: %w", selector, err)
+	}
+	return el, nil
+}
+
+// FindAll locates all elements matching the CSS selector in the active session
+func (b *webDriverBrowser) FindAll(selector string) ([]Element, error) {
+	return b.FindAllContext(context.Background(), selector)
+}
+
+// FindAllContext locates all elements matching the CSS selector, giving up when ctx is done.
+// The returned elements perform their actions under ctx.
+func (b *webDriverBrowser) FindAllContext(ctx context.Context, selector string) ([]Element, error) {
+	if err := b.checkSession(); err != nil {
+		return nil, err
+	}
+	els, err := webDriverFindAll(ctx, b.session, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find elements %q: %w", selector, err)
+	}
+	elements := make([]Element, len(els))
+	for i, el := range els {
+		elements[i] = &webDriverElement{el: el, browser: b, ctx: ctx}
+	}
+	return elements, nil
+}
+
+// Close ends the session and shuts down the driver process
+func (b *webDriverBrowser) Close() error {
+	return b.CloseContext(context.Background())
+}
+
+// CloseContext ends the session and shuts down the driver process.
+// The driver is killed even if ending the session fails or ctx is done.
+func (b *webDriverBrowser) CloseContext(ctx context.Context) error {
+	harErr := b.StopHAR()
+	if b.bidi != nil {
+		b.bidi.Close()
+		b.bidi, b.intercept, b.routeEvents, b.harEvents = nil, "", false, false
+	}
+	if b.session != nil {
+		b.session.ReleaseActions(ctx)
+		if err := b.session.Delete(ctx); err != nil {
+			log.Printf("Failed to delete %s session: %v\n", b.name, err)
+		}
+		b.session = nil
+	}
+	var closeErr, pageErr error
+	if b.cmd != nil && b.cmd.Process != nil {
+		if err := b.cmd.Process.Kill(); err != nil {
+			closeErr = fmt.Errorf("failed to close %s: %w", b.name, err)
+		} else {
+			b.cmd.Wait()
+			b.cmd = nil
+			log.Printf("%s browser closed successfully.\n", b.name)
+		}
+	}
+	if b.ownsDownloadDir {
+		os.RemoveAll(b.downloadDir)
+		b.downloadDir, b.ownsDownloadDir = "", false
+	}
+	if b.failOnPageError {
+		pageErr = b.CheckPageErrors()
+	}
+	return errors.Join(closeErr, harErr, pageErr)
+}
+
+// webDriverElement wraps a W3C WebDriver element reference bound to the context it was found with
+type webDriverElement struct {
+	el      *webdriver.Element
+	browser *webDriverBrowser
+	ctx     context.Context
+}
+
+func (e *webDriverElement) Click() error {
+	if err := waitActionable(e.ctx, e.browser.wait, e); err != nil {
+		return err
+	}
+	e.browser.delay(e.ctx)
+	return e.el.Click(e.ctx)
+}
+
+func (e *webDriverElement) Type(text string) error {
+	if err := waitActionable(e.ctx, e.browser.wait, e); err != nil {
+		return err
+	}
+	e.browser.delay(e.ctx)
+	return e.el.SendKeys(e.ctx, text)
+}
+
+func (e *webDriverElement) Text() (string, error) {
+	return e.el.Text(e.ctx)
+}
+
+func (e *webDriverElement) Attribute(name string) (string, error) {
+	value, err := e.el.Attribute(e.ctx, name)
+	if err != nil || value == nil {
+		return "", err
+	}
+	return *value, nil
+}
+
+func (e *webDriverElement) Visible() (bool, error) {
+	return e.el.Displayed(e.ctx)
+}
+
+func (e *webDriverElement) Enabled() (bool, error) {
+	return e.el.Enabled(e.ctx)
+}
@@ -1,117 +1,139 @@
 package browsers
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
 	"os/exec"
 	"time"
+
+	"github.com/valdemart123/go-owl/config"
+	"github.com/valdemart123/go-owl/webdriver"
 )
 
 // WebKit struct for Safari automation
 type WebKit struct {
-	cmd       *exec.Cmd
-	sessionID string
+	webDriverBrowser
+
+	// DriverURL is the address safaridriver listens on, defaults to webdriver.DefaultURL
+	DriverURL string
+	// Options are the launch options from the config
+	Options config.LaunchConfig
 }
 
 // Launch starts a new Safari (WebKit) instance using safaridriver
 func (w *WebKit) Launch() error {
+	return w.LaunchContext(context.Background())
+}
+
+// LaunchContext starts a new Safari (WebKit) instance, giving up when ctx is done
+func (w *WebKit) LaunchContext(ctx context.Context) error {
 	log.Println("Launching Safari (WebKit)...")
+	w.name = "Safari"
+	w.client = webdriver.NewClient(w.DriverURL)
+	w.slowMo = time.Duration(w.Options.SlowMo) * time.Millisecond
+
+	// Safari has no preferences for the rest of a device, only its viewport is emulated
+	device, err := deviceFromConfig(w.Options.Emulation)
+	if err != nil {
+		return err
+	}
+	w.device = Device{Viewport: device.Viewport}
+	if device != w.device {
+		log.Println("Safari emulates only the viewport of a device, ignoring its other settings.")
+	}
+
+	driver := "safaridriver"
+	if w.Options.Binary != "" {
+		driver = w.Options.Binary
+	}
 
 	// Ensure WebKit automation is enabled
-	enableCmd := exec.Command("safaridriver", "--enable")
+	enableCmd := exec.Command(driver, "--enable")
 	if err := enableCmd.Run(); err != nil {
 		return fmt.Errorf("failed to enable Safari WebDriver: %w", err)
 	}
 
-	// Start safaridriver on a specified port (e.g., 4444)
-	w.cmd = exec.Command("safaridriver", "--port=4444")
+	// Start safaridriver on the driver port
+	w.cmd = exec.Command(driver, "--port="+driverPort(w.client.BaseURL))
 	if err := w.cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start Safari WebDriver: %w", err)
 	}
 
-	// Wait briefly to ensure safaridriver is ready
-	time.Sleep(2 * time.Second)
+	// Wait for safaridriver to accept sessions
+	if err := w.waitDriverReady(ctx); err != nil {
+		w.Close()
+		return fmt.Errorf("safaridriver did not become ready: %w", err)
+	}
 
 	// Create a new session
-	sessionID, err := w.createSession()
-	if err != nil {
+	if err := w.createSession(ctx, w.capabilities()); err != nil {
+		w.Close()
 		return fmt.Errorf("failed to create session: %w", err)
 	}
-	w.sessionID = sessionID
-	log.Println("Safari session created:", w.sessionID)
-	return nil
-}
-
-// createSession sends a request to safaridriver to create a new session
-func (w *WebKit) createSession() (string, error) {
-	url := "http://localhost:4444/session"
-	payload := map[string]interface{}{
-		"capabilities": map[string]interface{}{
-			"alwaysMatch": map[string]interface{}{
-				"browserName": "safari",
-			},
-		},
+	if err := w.applyOptions(ctx, w.Options); err != nil {
+		w.Close()
+		return err
 	}
-	jsonPayload, _ := json.Marshal(payload)
-
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return "", fmt.Errorf("failed to create session: %w", err)
+	// Contexts made by NewContext are not recorded, they would overwrite the same file
+	if w.Options.RecordHAR != "" {
+		if err := w.StartHAR(w.Options.RecordHAR); err != nil {
+			w.Close()
+			return err
+		}
 	}
-	defer resp.Body.Close()
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode session response: %w", err)
-	}
+	log.Println("Safari session created:", w.session.ID)
+	return nil
+}
 
-	// Expecting the session response in the form: { "value": { "sessionId": "..." } }
-	sessionData, ok := result["value"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid session response format")
-	}
-	sessionID, ok := sessionData["sessionId"].(string)
-	if !ok {
-		return "", fmt.Errorf("invalid session response format: sessionId not found")
+// NewContext opens a separate Safari session with its own cookies and storage.
+// safaridriver allows only one session at a time, so this fails while another one is active.
+func (w *WebKit) NewContext() (Browser, error) {
+	ctx := context.Background()
+	isolated := &WebKit{Options: w.Options}
+	if err := w.newContext(ctx, w.capabilities(), &isolated.webDriverBrowser); err != nil {
+		return nil, fmt.Errorf("failed to create Safari context: %w", err)
 	}
 
-	return sessionID, nil
-}
-
-// OpenURL navigates to the given URL in the active Safari session
-func (w *WebKit) OpenURL(urlStr string) error {
-	if w.sessionID == "" {
-		return fmt.Errorf("no active session, start Safari first")
+	isolated.DriverURL = isolated.client.BaseURL
+	if err := isolated.applyOptions(ctx, w.Options); err != nil {
+		isolated.Close()
+		return nil, err
 	}
+	return isolated, nil
+}
 
-	requestURL := fmt.Sprintf("http://localhost:4444/session/%s/url", w.sessionID)
-	payload := map[string]string{"url": urlStr}
-	jsonPayload, _ := json.Marshal(payload)
+// capabilities translates the launch options into Safari capabilities.
+// Safari has no equivalent for most process level options, those are reported and skipped.
+func (w *WebKit) capabilities() webdriver.Capabilities {
+	opts := w.Options
+	extensions := map[string]interface{}{}
 
-	resp, err := http.Post(requestURL, "application/json", bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return fmt.Errorf("failed to open URL: %w", err)
+	if opts.Devtools {
+		extensions["safari:automaticInspection"] = true
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to navigate to URL, status code: %d", resp.StatusCode)
+	unsupported := []struct {
+		name string
+		set  bool
+	}{
+		{"headless", opts.IsHeadless(false)},
+		{"args", len(opts.Args) > 0},
+		{"userDataDir", opts.UserDataDir != ""},
+		{"downloadDir", opts.DownloadDir != ""},
+		{"locale", opts.Locale != ""},
+		{"timezone", opts.Timezone != ""},
+		{"geolocation", opts.Geolocation != nil},
+		{"permissions", len(opts.Permissions) > 0},
+		{"colorScheme", opts.ColorScheme != ""},
+		{"reducedMotion", opts.ReducedMotion != ""},
 	}
-
-	log.Println("Opened URL in Safari:", urlStr)
-	return nil
-}
-
-// Close shuts down the Safari WebDriver instance
-func (w *WebKit) Close() error {
-	if w.cmd != nil && w.cmd.Process != nil {
-		if err := w.cmd.Process.Kill(); err != nil {
-			return fmt.Errorf("failed to close Safari WebDriver: %w", err)
+	for _, option := range unsupported {
+		if option.set {
+			log.Printf("Launch option %q is not supported by Safari, ignoring it.\n", option.name)
 		}
-		log.Println("Safari WebDriver closed successfully.")
 	}
-	return nil
+
+	return webdriver.Capabilities{BrowserName: "safari", Extensions: extensions}
 }
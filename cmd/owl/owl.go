@@ -20,7 +20,7 @@ import (
 // Run starts the CLI tool
 func Run() {
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: owl <command>\nAvailable commands:\n  setup  Install or update required browser drivers")
+		fmt.Println("Usage: owl <command>\nAvailable commands:\n  setup              Install or update required browser drivers\n  show-trace <file>  Open a trace archive in the local trace viewer")
 		os.Exit(1)
 	}
 
@@ -30,6 +30,15 @@ func Run() {
 			fmt.Printf("Setup encountered errors: %v\n", err)
 			os.Exit(1)
 		}
+	case "show-trace":
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: owl show-trace <file>")
+			os.Exit(1)
+		}
+		if err := showTrace(os.Args[2]); err != nil {
+			fmt.Printf("Trace viewer stopped: %v\n", err)
+			os.Exit(1)
+		}
 	default:
 		fmt.Println("Unknown command:", os.Args[1])
 		os.Exit(1)
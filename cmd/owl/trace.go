@@ -0,0 +1,40 @@
+package main
+
+import (
+	"archive/zip"
+	_ "embed"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+//go:embed trace_viewer.html
+var traceViewer []byte
+
+// showTrace serves the trace archive with the trace viewer on a local port until interrupted.
+func showTrace(path string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open trace: %w", err)
+	}
+	defer archive.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(traceViewer)
+	})
+	// The viewer reads trace.json and the files it references from the archive
+	mux.Handle("/trace/", http.StripPrefix("/trace/", http.FileServer(http.FS(archive))))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start trace viewer: %w", err)
+	}
+	fmt.Printf("Serving %s at http://%s/\nPress Ctrl+C to stop.\n", path, listener.Addr())
+	return http.Serve(listener, mux)
+}
@@ -12,6 +12,90 @@ type Config struct {
 	Browser struct {
 		Type string `json:"type"`
 	} `json:"browser"`
+	Launch LaunchConfig `json:"launch"`
+}
+
+// LaunchConfig holds the options used when starting a browser
+type LaunchConfig struct {
+	// Headless runs the browser without a visible window, nil keeps the browser's default
+	Headless *bool `json:"headless"`
+	// WindowSize is the initial size of the browser window in pixels
+	WindowSize WindowSize `json:"windowSize"`
+	// Binary is the path of the browser executable, or of safaridriver for WebKit
+	Binary string `json:"binary"`
+	// Args are extra command line arguments passed to the browser
+	Args []string `json:"args"`
+	// SlowMo delays every navigation and element action by the given milliseconds
+	SlowMo int `json:"slowMo"`
+	// Devtools opens the developer tools on start
+	Devtools bool `json:"devtools"`
+	// UserDataDir is the profile directory, kept between runs when set
+	UserDataDir string `json:"userDataDir"`
+	// DownloadDir is where downloaded files are saved
+	DownloadDir string `json:"downloadDir"`
+	// Locale is the browser UI and Accept-Language locale, such as "en-US"
+	Locale string `json:"locale"`
+	// Timezone is the IANA timezone of the browser process, such as "Europe/Berlin"
+	Timezone string `json:"timezone"`
+	// StorageState is a file written by SaveStorageState, restored into the browser after launch.
+	// It holds the cookies and the web storage of every origin visited before it was saved.
+	StorageState string `json:"storageState"`
+	// RecordHAR is a file the network traffic of the launched browser is recorded into, written on close
+	RecordHAR string `json:"recordHar"`
+	// ReplayHAR is a HAR file whose recorded responses answer the browser's requests instead of the network
+	ReplayHAR string `json:"replayHar"`
+	// FailOnPageError makes Close report the uncaught errors the pages threw
+	FailOnPageError bool `json:"failOnPageError"`
+	// Emulation is the device the pages emulate
+	Emulation Emulation `json:"emulation"`
+	// Geolocation is the position the pages report, nil keeps the real one
+	Geolocation *Geolocation `json:"geolocation"`
+	// Permissions are granted to every origin, such as "geolocation", "notifications" or "camera"
+	Permissions []string `json:"permissions"`
+	// ColorScheme is the emulated prefers-color-scheme, "light" or "dark"
+	ColorScheme string `json:"colorScheme"`
+	// ReducedMotion is the emulated prefers-reduced-motion, "reduce" or "no-preference"
+	ReducedMotion string `json:"reducedMotion"`
+	// VideoDir is where a video of every page is recorded to, Chrome only
+	VideoDir string `json:"videoDir"`
+	// RetainVideoOnFailure keeps the videos only of runs marked failed or closed with page errors
+	RetainVideoOnFailure bool `json:"retainVideoOnFailure"`
+	// TraceDir is where a trace archive of the browser's actions is saved on close
+	TraceDir string `json:"traceDir"`
+}
+
+// Geolocation is a position in degrees, Accuracy is in meters
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+// Emulation describes an emulated device. Device names a built-in descriptor,
+// such as "iPhone 14", and the other fields override its values.
+type Emulation struct {
+	Device string `json:"device"`
+	// Viewport is the size of the page content area in CSS pixels
+	Viewport WindowSize `json:"viewport"`
+	// DeviceScaleFactor is the number of device pixels per CSS pixel
+	DeviceScaleFactor float64 `json:"deviceScaleFactor"`
+	IsMobile          *bool   `json:"isMobile"`
+	HasTouch          *bool   `json:"hasTouch"`
+	UserAgent         string  `json:"userAgent"`
+}
+
+// WindowSize is a window size in pixels
+type WindowSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// IsHeadless reports whether the browser should run headless, using def when not configured
+func (l LaunchConfig) IsHeadless(def bool) bool {
+	if l.Headless == nil {
+		return def
+	}
+	return *l.Headless
 }
 
 // LoadConfig reads and parses the JSON config file
@@ -32,4 +116,4 @@ func LoadConfig() Config {
 // LoadBrowserType retrieves the browser type from the config
 func LoadBrowserType() string {
 	return LoadConfig().Browser.Type
-}
\ No newline at end of file
+}
@@ -0,0 +1,79 @@
+package webdriver
+
+import "encoding/json"
+
+// Input source types for action sequences
+const (
+	SourceNone    = "none"
+	SourceKey     = "key"
+	SourcePointer = "pointer"
+	SourceWheel   = "wheel"
+)
+
+// Action types for action sequences
+const (
+	ActionPause       = "pause"
+	ActionKeyDown     = "keyDown"
+	ActionKeyUp       = "keyUp"
+	ActionPointerDown = "pointerDown"
+	ActionPointerUp   = "pointerUp"
+	ActionPointerMove = "pointerMove"
+	ActionScroll      = "scroll"
+)
+
+// ActionSequence is the list of actions for a single input source
+type ActionSequence struct {
+	Type       string             `json:"type"`
+	ID         string             `json:"id"`
+	Parameters *PointerParameters `json:"parameters,omitempty"`
+	Actions    []Action           `json:"actions"`
+}
+
+// PointerParameters selects the kind of pointer device
+type PointerParameters struct {
+	PointerType string `json:"pointerType"`
+}
+
+// Action is a single tick of an action sequence.
+// Origin is "viewport", "pointer" or an *Element.
+type Action struct {
+	Type     string
+	Duration int
+	Value    string
+	Button   int
+	X        float64
+	Y        float64
+	DeltaX   float64
+	DeltaY   float64
+	Origin   interface{}
+}
+
+// MarshalJSON encodes only the fields that apply to the action type
+func (a Action) MarshalJSON() ([]byte, error) {
+	action := map[string]interface{}{"type": a.Type}
+	switch a.Type {
+	case ActionPause:
+		action["duration"] = a.Duration
+	case ActionKeyDown, ActionKeyUp:
+		action["value"] = a.Value
+	case ActionPointerDown, ActionPointerUp:
+		action["button"] = a.Button
+	case ActionPointerMove:
+		action["duration"] = a.Duration
+		action["x"] = int(a.X)
+		action["y"] = int(a.Y)
+		if a.Origin != nil {
+			action["origin"] = a.Origin
+		}
+	case ActionScroll:
+		action["duration"] = a.Duration
+		action["x"] = int(a.X)
+		action["y"] = int(a.Y)
+		action["deltaX"] = int(a.DeltaX)
+		action["deltaY"] = int(a.DeltaY)
+		if a.Origin != nil {
+			action["origin"] = a.Origin
+		}
+	}
+	return json.Marshal(action)
+}
@@ -0,0 +1,213 @@
+package webdriver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-rod/rod/lib/cdp"
+)
+
+// BiDi is a WebDriver BiDi connection to a session. It carries the events and
+// commands the classic protocol lacks, such as network interception.
+type BiDi struct {
+	ws *cdp.WebSocket
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	nextID   int
+	pending  map[int]chan bidiMessage
+	handlers map[string][]func(json.RawMessage)
+	events   []bidiMessage
+	err      error // set once the connection is lost
+}
+
+// bidiCommand is a command sent to the remote end
+type bidiCommand struct {
+	ID     int         `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+// bidiMessage is a command response or an event sent by the remote end
+type bidiMessage struct {
+	ID         *int            `json:"id"`
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params"`
+	Result     json.RawMessage `json:"result"`
+	Code       string          `json:"error"`
+	Message    string          `json:"message"`
+	Stacktrace string          `json:"stacktrace"`
+}
+
+// ConnectBiDi opens the BiDi connection of a session created with the WebSocketURL capability
+func (s *Session) ConnectBiDi(ctx context.Context) (*BiDi, error) {
+	url, _ := s.Capabilities["webSocketUrl"].(string)
+	if url == "" {
+		return nil, &Error{Code: CodeUnsupportedOperation, Message: "the session offers no BiDi connection"}
+	}
+
+	// Rod's minimal WebSocket client is enough for the driver's local connection
+	ws := &cdp.WebSocket{}
+	if err := ws.Connect(ctx, url, nil); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+
+	b := &BiDi{
+		ws:       ws,
+		pending:  map[int]chan bidiMessage{},
+		handlers: map[string][]func(json.RawMessage){},
+	}
+	b.cond = sync.NewCond(&b.mu)
+	go b.read()
+	go b.dispatch()
+	return b, nil
+}
+
+// Call sends a command and decodes its result into result, which may be nil
+func (b *BiDi) Call(ctx context.Context, method string, params, result interface{}) error {
+	if params == nil {
+		params = struct{}{}
+	}
+
+	b.mu.Lock()
+	if b.err != nil {
+		b.mu.Unlock()
+		return b.err
+	}
+	b.nextID++
+	id := b.nextID
+	reply := make(chan bidiMessage, 1)
+	b.pending[id] = reply
+	b.mu.Unlock()
+
+	data, err := json.Marshal(bidiCommand{ID: id, Method: method, Params: params})
+	if err != nil {
+		b.forget(id)
+		return fmt.Errorf("failed to encode %s command: %w", method, err)
+	}
+	if err := b.ws.Send(data); err != nil {
+		b.forget(id)
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		b.forget(id)
+		return ctx.Err()
+	case msg, ok := <-reply:
+		if !ok {
+			return b.closed()
+		}
+		if msg.Code != "" {
+			return &Error{Code: msg.Code, Message: msg.Message, Stacktrace: msg.Stacktrace}
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// On registers a handler for the event, which is only sent once subscribed to.
+// Handlers run one at a time in the order the events arrive.
+func (b *BiDi) On(event string, handler func(params json.RawMessage)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Copy on write, dispatch runs the handlers it read without holding the lock
+	handlers := make([]func(json.RawMessage), 0, len(b.handlers[event])+1)
+	b.handlers[event] = append(append(handlers, b.handlers[event]...), handler)
+}
+
+// onEvent registers a handler receiving the decoded event parameters
+func onEvent[T any](b *BiDi, event string, handler func(T)) {
+	b.On(event, func(params json.RawMessage) {
+		var value T
+		if err := json.Unmarshal(params, &value); err == nil {
+			handler(value)
+		}
+	})
+}
+
+// Subscribe asks the remote end to send the events, given by name or module, on this connection
+func (b *BiDi) Subscribe(ctx context.Context, events ...string) error {
+	return b.Call(ctx, "session.subscribe", map[string]interface{}{"events": events}, nil)
+}
+
+// Close closes the connection, failing the commands still waiting for a response
+func (b *BiDi) Close() error {
+	return b.ws.Close()
+}
+
+func (b *BiDi) forget(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pending, id)
+}
+
+func (b *BiDi) closed() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// read receives messages until the connection is lost, routing responses to their commands
+func (b *BiDi) read() {
+	for {
+		data, err := b.ws.Read()
+		if err != nil {
+			b.mu.Lock()
+			b.err = fmt.Errorf("BiDi connection lost: %w", err)
+			for id, reply := range b.pending {
+				close(reply)
+				delete(b.pending, id)
+			}
+			b.cond.Broadcast()
+			b.mu.Unlock()
+			return
+		}
+
+		var msg bidiMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		b.mu.Lock()
+		switch {
+		case msg.ID != nil:
+			if reply, ok := b.pending[*msg.ID]; ok {
+				reply <- msg
+				delete(b.pending, *msg.ID)
+			}
+		case msg.Method != "":
+			b.events = append(b.events, msg)
+			b.cond.Signal()
+		}
+		b.mu.Unlock()
+	}
+}
+
+// dispatch runs the event handlers apart from read, so handlers can send commands
+func (b *BiDi) dispatch() {
+	for {
+		b.mu.Lock()
+		for len(b.events) == 0 && b.err == nil {
+			b.cond.Wait()
+		}
+		if len(b.events) == 0 {
+			b.mu.Unlock()
+			return
+		}
+		event := b.events[0]
+		b.events = b.events[1:]
+		handlers := b.handlers[event.Method]
+		b.mu.Unlock()
+
+		for _, handler := range handlers {
+			handler(event.Params)
+		}
+	}
+}
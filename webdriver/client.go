@@ -0,0 +1,142 @@
+// Package webdriver provides a client for the W3C WebDriver protocol
+// used to drive Geckodriver, safaridriver and other remote ends.
+package webdriver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the address drivers are expected to listen on when none is given
+const DefaultURL = "http://localhost:4444"
+
+// Client talks to a WebDriver remote end over HTTP
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the remote end at baseURL
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Status reports whether the remote end is ready to create new sessions
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var status Status
+	if err := c.do(ctx, http.MethodGet, "/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// WaitReady polls the status endpoint every interval until the remote end is ready or ctx is done
+func (c *Client) WaitReady(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := c.Status(ctx)
+		if err == nil && status.Ready {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("remote end not ready: %s", status.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// NewSession creates a new session with the requested capabilities
+func (c *Client) NewSession(ctx context.Context, caps Capabilities) (*Session, error) {
+	payload := newSessionRequest{Capabilities: capabilitiesRequest{AlwaysMatch: caps}}
+
+	var result struct {
+		SessionID    string                 `json:"sessionId"`
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/session", payload, &result); err != nil {
+		return nil, err
+	}
+	if result.SessionID == "" {
+		return nil, fmt.Errorf("invalid session response format: sessionId not found")
+	}
+
+	return &Session{ID: result.SessionID, Capabilities: result.Capabilities, client: c}, nil
+}
+
+// do sends a command to the remote end and decodes the response value into result
+func (c *Client) do(ctx context.Context, method, path string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s %s request: %w", method, path, err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s response: %w", method, path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return parseError(resp.StatusCode, data)
+	}
+
+	if result == nil {
+		return nil
+	}
+	var envelope struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	if err := json.Unmarshal(envelope.Value, result); err != nil {
+		return fmt.Errorf("failed to decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+type newSessionRequest struct {
+	Capabilities capabilitiesRequest `json:"capabilities"`
+}
+
+type capabilitiesRequest struct {
+	AlwaysMatch Capabilities `json:"alwaysMatch"`
+}
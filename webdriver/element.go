@@ -0,0 +1,204 @@
+package webdriver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Web element and shadow root reference keys defined by the specification
+const (
+	ElementKey    = "element-6066-11e4-a52e-4f735466cecf"
+	ShadowRootKey = "shadow-6066-11e4-a52e-4f735466cecf"
+)
+
+// Element is a reference to an element within a session
+type Element struct {
+	ID      string
+	session *Session
+}
+
+// MarshalJSON encodes the element as a web element reference so it can be passed to scripts
+func (e *Element) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{ElementKey: e.ID})
+}
+
+// Session returns the session the element belongs to
+func (e *Element) Session() *Session {
+	return e.session
+}
+
+func (e *Element) do(ctx context.Context, method, path string, payload, result interface{}) error {
+	return e.session.do(ctx, method, "/element/"+e.ID+path, payload, result)
+}
+
+// FindElement locates the first descendant matching the selector
+func (e *Element) FindElement(ctx context.Context, using, value string) (*Element, error) {
+	var ref elementRef
+	if err := e.do(ctx, http.MethodPost, "/element", locator{using, value}, &ref); err != nil {
+		return nil, err
+	}
+	return e.session.element(ref), nil
+}
+
+// FindElements locates all descendants matching the selector
+func (e *Element) FindElements(ctx context.Context, using, value string) ([]*Element, error) {
+	var refs []elementRef
+	if err := e.do(ctx, http.MethodPost, "/elements", locator{using, value}, &refs); err != nil {
+		return nil, err
+	}
+	return e.session.elements(refs), nil
+}
+
+// ShadowRoot returns the open shadow root attached to the element
+func (e *Element) ShadowRoot(ctx context.Context) (*ShadowRoot, error) {
+	var ref map[string]string
+	if err := e.do(ctx, http.MethodGet, "/shadow", nil, &ref); err != nil {
+		return nil, err
+	}
+	return &ShadowRoot{ID: ref[ShadowRootKey], session: e.session}, nil
+}
+
+// Selected reports whether a checkbox, radio or option is selected
+func (e *Element) Selected(ctx context.Context) (bool, error) {
+	var selected bool
+	err := e.do(ctx, http.MethodGet, "/selected", nil, &selected)
+	return selected, err
+}
+
+// Attribute returns the attribute value, or nil if the attribute is not set
+func (e *Element) Attribute(ctx context.Context, name string) (*string, error) {
+	var value *string
+	err := e.do(ctx, http.MethodGet, "/attribute/"+url.PathEscape(name), nil, &value)
+	return value, err
+}
+
+// Property decodes the DOM property value into result
+func (e *Element) Property(ctx context.Context, name string, result interface{}) error {
+	return e.do(ctx, http.MethodGet, "/property/"+url.PathEscape(name), nil, result)
+}
+
+// CSSValue returns the computed value of the CSS property
+func (e *Element) CSSValue(ctx context.Context, name string) (string, error) {
+	var value string
+	err := e.do(ctx, http.MethodGet, "/css/"+url.PathEscape(name), nil, &value)
+	return value, err
+}
+
+// Text returns the rendered text of the element
+func (e *Element) Text(ctx context.Context) (string, error) {
+	var text string
+	err := e.do(ctx, http.MethodGet, "/text", nil, &text)
+	return text, err
+}
+
+// TagName returns the lowercase tag name of the element
+func (e *Element) TagName(ctx context.Context) (string, error) {
+	var name string
+	err := e.do(ctx, http.MethodGet, "/name", nil, &name)
+	return name, err
+}
+
+// Rect returns the position and size of the element
+func (e *Element) Rect(ctx context.Context) (*Rect, error) {
+	var rect Rect
+	if err := e.do(ctx, http.MethodGet, "/rect", nil, &rect); err != nil {
+		return nil, err
+	}
+	return &rect, nil
+}
+
+// Enabled reports whether the element is enabled
+func (e *Element) Enabled(ctx context.Context) (bool, error) {
+	var enabled bool
+	err := e.do(ctx, http.MethodGet, "/enabled", nil, &enabled)
+	return enabled, err
+}
+
+// Displayed reports whether the element is visible to the user
+func (e *Element) Displayed(ctx context.Context) (bool, error) {
+	var displayed bool
+	err := e.do(ctx, http.MethodGet, "/displayed", nil, &displayed)
+	return displayed, err
+}
+
+// ComputedRole returns the accessibility role of the element
+func (e *Element) ComputedRole(ctx context.Context) (string, error) {
+	var role string
+	err := e.do(ctx, http.MethodGet, "/computedrole", nil, &role)
+	return role, err
+}
+
+// ComputedLabel returns the accessible name of the element
+func (e *Element) ComputedLabel(ctx context.Context) (string, error) {
+	var label string
+	err := e.do(ctx, http.MethodGet, "/computedlabel", nil, &label)
+	return label, err
+}
+
+// Click scrolls the element into view and clicks its center
+func (e *Element) Click(ctx context.Context) error {
+	return e.do(ctx, http.MethodPost, "/click", struct{}{}, nil)
+}
+
+// Clear empties an editable element
+func (e *Element) Clear(ctx context.Context) error {
+	return e.do(ctx, http.MethodPost, "/clear", struct{}{}, nil)
+}
+
+// SendKeys types text into the element
+func (e *Element) SendKeys(ctx context.Context, text string) error {
+	return e.do(ctx, http.MethodPost, "/value", map[string]string{"text": text}, nil)
+}
+
+// Screenshot captures the element's bounding box as PNG
+func (e *Element) Screenshot(ctx context.Context) ([]byte, error) {
+	var encoded string
+	if err := e.do(ctx, http.MethodGet, "/screenshot", nil, &encoded); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	return data, nil
+}
+
+// ShadowRoot is a reference to an open shadow root within a session
+type ShadowRoot struct {
+	ID      string
+	session *Session
+}
+
+// MarshalJSON encodes the shadow root as a reference so it can be passed to scripts
+func (r *ShadowRoot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{ShadowRootKey: r.ID})
+}
+
+// FindElement locates the first element in the shadow tree matching the selector
+func (r *ShadowRoot) FindElement(ctx context.Context, using, value string) (*Element, error) {
+	var ref elementRef
+	if err := r.session.do(ctx, http.MethodPost, "/shadow/"+r.ID+"/element", locator{using, value}, &ref); err != nil {
+		return nil, err
+	}
+	return r.session.element(ref), nil
+}
+
+// FindElements locates all elements in the shadow tree matching the selector
+func (r *ShadowRoot) FindElements(ctx context.Context, using, value string) ([]*Element, error) {
+	var refs []elementRef
+	if err := r.session.do(ctx, http.MethodPost, "/shadow/"+r.ID+"/elements", locator{using, value}, &refs); err != nil {
+		return nil, err
+	}
+	return r.session.elements(refs), nil
+}
+
+type locator struct {
+	Using string `json:"using"`
+	Value string `json:"value"`
+}
+
+type elementRef map[string]string
@@ -0,0 +1,56 @@
+package webdriver
+
+import "context"
+
+// Permission states accepted by SetPermission
+const (
+	PermissionGranted = "granted"
+	PermissionDenied  = "denied"
+	PermissionPrompt  = "prompt"
+)
+
+// defaultUserContext is the user context sessions browse in, overrides target it
+var defaultUserContext = []string{"default"}
+
+// GeolocationCoordinates is a position reported by the geolocation API, Accuracy is in meters
+type GeolocationCoordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Accuracy  float64 `json:"accuracy,omitempty"`
+}
+
+// SetGeolocationOverride makes the pages of the session report the position, nil removes the override
+func (b *BiDi) SetGeolocationOverride(ctx context.Context, coordinates *GeolocationCoordinates) error {
+	params := map[string]interface{}{"coordinates": coordinates, "userContexts": defaultUserContext}
+	return b.Call(ctx, "emulation.setGeolocationOverride", params, nil)
+}
+
+// SetTimezoneOverride makes the pages of the session use the IANA timezone, empty removes the override
+func (b *BiDi) SetTimezoneOverride(ctx context.Context, timezone string) error {
+	params := map[string]interface{}{"timezone": nullString(timezone), "userContexts": defaultUserContext}
+	return b.Call(ctx, "emulation.setTimezoneOverride", params, nil)
+}
+
+// SetLocaleOverride makes the pages of the session use the locale, empty removes the override
+func (b *BiDi) SetLocaleOverride(ctx context.Context, locale string) error {
+	params := map[string]interface{}{"locale": nullString(locale), "userContexts": defaultUserContext}
+	return b.Call(ctx, "emulation.setLocaleOverride", params, nil)
+}
+
+// SetPermission sets the state of the permission, such as "geolocation", for the origin
+func (b *BiDi) SetPermission(ctx context.Context, name, state, origin string) error {
+	params := map[string]interface{}{
+		"descriptor": map[string]string{"name": name},
+		"state":      state,
+		"origin":     origin,
+	}
+	return b.Call(ctx, "permissions.setPermission", params, nil)
+}
+
+// nullString returns nil for the empty string, which BiDi expects as null
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Error codes defined by the W3C WebDriver specification
+const (
+	CodeElementClickIntercepted = "element click intercepted"
+	CodeElementNotInteractable  = "element not interactable"
+	CodeInsecureCertificate     = "insecure certificate"
+	CodeInvalidArgument         = "invalid argument"
+	CodeInvalidCookieDomain     = "invalid cookie domain"
+	CodeInvalidElementState     = "invalid element state"
+	CodeInvalidSelector         = "invalid selector"
+	CodeInvalidSessionID        = "invalid session id"
+	CodeJavascriptError         = "javascript error"
+	CodeMoveTargetOutOfBounds   = "move target out of bounds"
+	CodeNoSuchAlert             = "no such alert"
+	CodeNoSuchCookie            = "no such cookie"
+	CodeNoSuchElement           = "no such element"
+	CodeNoSuchFrame             = "no such frame"
+	CodeNoSuchShadowRoot        = "no such shadow root"
+	CodeNoSuchWindow            = "no such window"
+	CodeScriptTimeout           = "script timeout"
+	CodeSessionNotCreated       = "session not created"
+	CodeStaleElementReference   = "stale element reference"
+	CodeDetachedShadowRoot      = "detached shadow root"
+	CodeTimeout                 = "timeout"
+	CodeUnableToSetCookie       = "unable to set cookie"
+	CodeUnableToCaptureScreen   = "unable to capture screen"
+	CodeUnexpectedAlertOpen     = "unexpected alert open"
+	CodeUnknownCommand          = "unknown command"
+	CodeUnknownError            = "unknown error"
+	CodeUnknownMethod           = "unknown method"
+	CodeUnsupportedOperation    = "unsupported operation"
+)
+
+// Sentinel errors for use with errors.Is, matched by error code
+var (
+	ErrElementClickIntercepted = &Error{Code: CodeElementClickIntercepted}
+	ErrElementNotInteractable  = &Error{Code: CodeElementNotInteractable}
+	ErrInsecureCertificate     = &Error{Code: CodeInsecureCertificate}
+	ErrInvalidArgument         = &Error{Code: CodeInvalidArgument}
+	ErrInvalidCookieDomain     = &Error{Code: CodeInvalidCookieDomain}
+	ErrInvalidElementState     = &Error{Code: CodeInvalidElementState}
+	ErrInvalidSelector         = &Error{Code: CodeInvalidSelector}
+	ErrInvalidSessionID        = &Error{Code: CodeInvalidSessionID}
+	ErrJavascriptError         = &Error{Code: CodeJavascriptError}
+	ErrMoveTargetOutOfBounds   = &Error{Code: CodeMoveTargetOutOfBounds}
+	ErrNoSuchAlert             = &Error{Code: CodeNoSuchAlert}
+	ErrNoSuchCookie            = &Error{Code: CodeNoSuchCookie}
+	ErrNoSuchElement           = &Error{Code: CodeNoSuchElement}
+	ErrNoSuchFrame             = &Error{Code: CodeNoSuchFrame}
+	ErrNoSuchShadowRoot        = &Error{Code: CodeNoSuchShadowRoot}
+	ErrNoSuchWindow            = &Error{Code: CodeNoSuchWindow}
+	ErrScriptTimeout           = &Error{Code: CodeScriptTimeout}
+	ErrSessionNotCreated       = &Error{Code: CodeSessionNotCreated}
+	ErrStaleElementReference   = &Error{Code: CodeStaleElementReference}
+	ErrDetachedShadowRoot      = &Error{Code: CodeDetachedShadowRoot}
+	ErrTimeout                 = &Error{Code: CodeTimeout}
+	ErrUnableToSetCookie       = &Error{Code: CodeUnableToSetCookie}
+	ErrUnableToCaptureScreen   = &Error{Code: CodeUnableToCaptureScreen}
+	ErrUnexpectedAlertOpen     = &Error{Code: CodeUnexpectedAlertOpen}
+	ErrUnknownCommand          = &Error{Code: CodeUnknownCommand}
+	ErrUnknownError            = &Error{Code: CodeUnknownError}
+	ErrUnknownMethod           = &Error{Code: CodeUnknownMethod}
+	ErrUnsupportedOperation    = &Error{Code: CodeUnsupportedOperation}
+)
+
+// Error is an error returned by the remote end
+type Error struct {
+	Status     int    `json:"-"`
+	Code       string `json:"error"`
+	Message    string `json:"message"`
+	Stacktrace string `json:"stacktrace"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is an *Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// parseError decodes the error payload of a failed command
+func parseError(status int, body []byte) error {
+	var envelope struct {
+		Value Error `json:"value"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Value.Code == "" {
+		return &Error{Status: status, Code: CodeUnknownError, Message: fmt.Sprintf("status code: %d", status)}
+	}
+	envelope.Value.Status = status
+	return &envelope.Value
+}
@@ -0,0 +1,70 @@
+package webdriver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    *Error
+		wantMsg string
+	}{
+		{
+			name:    "spec error",
+			status:  404,
+			body:    `{"value":{"error":"no such element","message":"Unable to locate .missing","stacktrace":"at find"}}`,
+			want:    ErrNoSuchElement,
+			wantMsg: "no such element: Unable to locate .missing",
+		},
+		{
+			name:    "error without message",
+			status:  500,
+			body:    `{"value":{"error":"javascript error"}}`,
+			want:    ErrJavascriptError,
+			wantMsg: "javascript error",
+		},
+		{
+			name:    "not json",
+			status:  502,
+			body:    `Bad Gateway`,
+			want:    ErrUnknownError,
+			wantMsg: "unknown error: status code: 502",
+		},
+		{
+			name:    "json without error code",
+			status:  500,
+			body:    `{"value":null}`,
+			want:    ErrUnknownError,
+			wantMsg: "unknown error: status code: 500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseError(tt.status, []byte(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("parseError() = %v, want it to match %v", err, tt.want)
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("parseError().Error() = %q, want %q", err.Error(), tt.wantMsg)
+			}
+			var wdErr *Error
+			if !errors.As(err, &wdErr) || wdErr.Status != tt.status {
+				t.Errorf("parseError() status = %v, want %d", wdErr, tt.status)
+			}
+		})
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	err := &Error{Code: CodeStaleElementReference, Message: "gone"}
+	if !errors.Is(err, ErrStaleElementReference) {
+		t.Error("error does not match the sentinel of its code")
+	}
+	if errors.Is(err, ErrNoSuchElement) {
+		t.Error("error matches the sentinel of another code")
+	}
+}
@@ -0,0 +1,46 @@
+package webdriver
+
+import "encoding/json"
+
+// Log entry types of the log.entryAdded event
+const (
+	LogEntryConsole    = "console"
+	LogEntryJavascript = "javascript"
+)
+
+// RemoteValue is a JavaScript value serialized by BiDi. Value holds primitives
+// and is absent for undefined, null and values passed by reference.
+type RemoteValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// StackFrame is a call frame of a stack trace, its line and column are zero-based
+type StackFrame struct {
+	URL          string `json:"url"`
+	FunctionName string `json:"functionName"`
+	LineNumber   int    `json:"lineNumber"`
+	ColumnNumber int    `json:"columnNumber"`
+}
+
+// StackTrace is the call stack of a log entry, innermost frame first
+type StackTrace struct {
+	CallFrames []StackFrame `json:"callFrames"`
+}
+
+// LogEntry is the log.entryAdded event. Console messages have Type "console" with
+// Method and Args set, uncaught errors have Type "javascript".
+type LogEntry struct {
+	Type       string        `json:"type"`
+	Level      string        `json:"level"`
+	Text       string        `json:"text"`
+	Timestamp  int64         `json:"timestamp"`
+	StackTrace *StackTrace   `json:"stackTrace"`
+	Method     string        `json:"method"`
+	Args       []RemoteValue `json:"args"`
+}
+
+// OnLogEntry registers a handler for the log.entryAdded event
+func (b *BiDi) OnLogEntry(handler func(LogEntry)) {
+	onEvent(b, "log.entryAdded", handler)
+}
@@ -0,0 +1,150 @@
+package webdriver
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// Network interception phases accepted by AddIntercept
+const (
+	PhaseBeforeRequestSent = "beforeRequestSent"
+	PhaseResponseStarted   = "responseStarted"
+	PhaseAuthRequired      = "authRequired"
+)
+
+// BytesValue is a header value or body: text, or base64 data when Type is "base64"
+type BytesValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// StringValue wraps the text into a BytesValue
+func StringValue(text string) *BytesValue {
+	return &BytesValue{Type: "string", Value: text}
+}
+
+// String returns the text of the value, decoding base64 data
+func (v BytesValue) String() string {
+	if v.Type == "base64" {
+		data, err := base64.StdEncoding.DecodeString(v.Value)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return v.Value
+}
+
+// Header is an HTTP header of a request or response
+type Header struct {
+	Name  string     `json:"name"`
+	Value BytesValue `json:"value"`
+}
+
+// RequestData describes the request of a network event
+type RequestData struct {
+	Request string   `json:"request"`
+	URL     string   `json:"url"`
+	Method  string   `json:"method"`
+	Headers []Header `json:"headers"`
+}
+
+// ResponseData describes the response of a network event
+type ResponseData struct {
+	URL        string   `json:"url"`
+	Protocol   string   `json:"protocol"`
+	Status     int      `json:"status"`
+	StatusText string   `json:"statusText"`
+	Headers    []Header `json:"headers"`
+	MimeType   string   `json:"mimeType"`
+	// BodySize is the encoded size of the body, nil when unknown
+	BodySize *int64 `json:"bodySize"`
+}
+
+// BeforeRequestSent is the network.beforeRequestSent event.
+// IsBlocked reports that an intercept paused the request until it is continued, answered or failed.
+// Timestamp is in milliseconds since the Unix epoch, as in all network events.
+type BeforeRequestSent struct {
+	Context    string      `json:"context"`
+	IsBlocked  bool        `json:"isBlocked"`
+	Intercepts []string    `json:"intercepts"`
+	Request    RequestData `json:"request"`
+	Timestamp  int64       `json:"timestamp"`
+}
+
+// ResponseCompleted is the network.responseCompleted event, sent once the response body is received
+type ResponseCompleted struct {
+	Context   string       `json:"context"`
+	Request   RequestData  `json:"request"`
+	Response  ResponseData `json:"response"`
+	Timestamp int64        `json:"timestamp"`
+}
+
+// FetchError is the network.fetchError event, sent when a request fails
+type FetchError struct {
+	Context   string      `json:"context"`
+	Request   RequestData `json:"request"`
+	ErrorText string      `json:"errorText"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+// ContinueRequest sends a paused request on, the fields left empty keep their original values
+type ContinueRequest struct {
+	Request string      `json:"request"`
+	URL     string      `json:"url,omitempty"`
+	Method  string      `json:"method,omitempty"`
+	Headers []Header    `json:"headers,omitempty"`
+	Body    *BytesValue `json:"body,omitempty"`
+}
+
+// ProvideResponse answers a paused request without contacting the server
+type ProvideResponse struct {
+	Request      string      `json:"request"`
+	StatusCode   int         `json:"statusCode,omitempty"`
+	ReasonPhrase string      `json:"reasonPhrase,omitempty"`
+	Headers      []Header    `json:"headers,omitempty"`
+	Body         *BytesValue `json:"body,omitempty"`
+}
+
+// OnBeforeRequestSent registers a handler for the network.beforeRequestSent event
+func (b *BiDi) OnBeforeRequestSent(handler func(BeforeRequestSent)) {
+	onEvent(b, "network.beforeRequestSent", handler)
+}
+
+// OnResponseCompleted registers a handler for the network.responseCompleted event
+func (b *BiDi) OnResponseCompleted(handler func(ResponseCompleted)) {
+	onEvent(b, "network.responseCompleted", handler)
+}
+
+// OnFetchError registers a handler for the network.fetchError event
+func (b *BiDi) OnFetchError(handler func(FetchError)) {
+	onEvent(b, "network.fetchError", handler)
+}
+
+// AddIntercept pauses the requests of the session at the phases and returns the intercept ID
+func (b *BiDi) AddIntercept(ctx context.Context, phases ...string) (string, error) {
+	var result struct {
+		Intercept string `json:"intercept"`
+	}
+	err := b.Call(ctx, "network.addIntercept", map[string]interface{}{"phases": phases}, &result)
+	return result.Intercept, err
+}
+
+// RemoveIntercept stops pausing the requests of the intercept
+func (b *BiDi) RemoveIntercept(ctx context.Context, intercept string) error {
+	return b.Call(ctx, "network.removeIntercept", map[string]string{"intercept": intercept}, nil)
+}
+
+// ContinueRequest sends a paused request on to the server
+func (b *BiDi) ContinueRequest(ctx context.Context, params ContinueRequest) error {
+	return b.Call(ctx, "network.continueRequest", params, nil)
+}
+
+// ProvideResponse answers a paused request
+func (b *BiDi) ProvideResponse(ctx context.Context, params ProvideResponse) error {
+	return b.Call(ctx, "network.provideResponse", params, nil)
+}
+
+// FailRequest fails a paused request with a network error
+func (b *BiDi) FailRequest(ctx context.Context, request string) error {
+	return b.Call(ctx, "network.failRequest", map[string]string{"request": request}, nil)
+}
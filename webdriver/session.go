@@ -0,0 +1,339 @@
+package webdriver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Session is an active WebDriver session
+type Session struct {
+	ID           string
+	Capabilities map[string]interface{}
+
+	// PromptHandler closes a user prompt that blocks a command, which is then retried once.
+	// Commands only fail this way when the session's unhandledPromptBehavior is "ignore".
+	PromptHandler func(ctx context.Context, s *Session) error
+
+	client *Client
+}
+
+func (s *Session) do(ctx context.Context, method, path string, payload, result interface{}) error {
+	err := s.client.do(ctx, method, "/session/"+s.ID+path, payload, result)
+	if s.PromptHandler == nil || !errors.Is(err, ErrUnexpectedAlertOpen) || strings.HasPrefix(path, "/alert") {
+		return err
+	}
+	if err := s.PromptHandler(ctx, s); err != nil {
+		return fmt.Errorf("failed to handle user prompt: %w", err)
+	}
+	return s.client.do(ctx, method, "/session/"+s.ID+path, payload, result)
+}
+
+func (s *Session) element(ref elementRef) *Element {
+	return s.Element(ref[ElementKey])
+}
+
+func (s *Session) elements(refs []elementRef) []*Element {
+	elements := make([]*Element, len(refs))
+	for i, ref := range refs {
+		elements[i] = s.element(ref)
+	}
+	return elements
+}
+
+// Element returns a reference to the element with the given web element ID
+func (s *Session) Element(id string) *Element {
+	return &Element{ID: id, session: s}
+}
+
+// Delete ends the session and closes all of its windows
+func (s *Session) Delete(ctx context.Context) error {
+	return s.client.do(ctx, http.MethodDelete, "/session/"+s.ID, nil, nil)
+}
+
+// Timeouts returns the session timeouts
+func (s *Session) Timeouts(ctx context.Context) (*Timeouts, error) {
+	var timeouts Timeouts
+	if err := s.do(ctx, http.MethodGet, "/timeouts", nil, &timeouts); err != nil {
+		return nil, err
+	}
+	return &timeouts, nil
+}
+
+// SetTimeouts updates the session timeouts
+func (s *Session) SetTimeouts(ctx context.Context, timeouts Timeouts) error {
+	return s.do(ctx, http.MethodPost, "/timeouts", timeouts, nil)
+}
+
+// Navigate loads the URL in the current top-level browsing context
+func (s *Session) Navigate(ctx context.Context, url string) error {
+	return s.do(ctx, http.MethodPost, "/url", map[string]string{"url": url}, nil)
+}
+
+// CurrentURL returns the URL of the current top-level browsing context
+func (s *Session) CurrentURL(ctx context.Context) (string, error) {
+	var url string
+	err := s.do(ctx, http.MethodGet, "/url", nil, &url)
+	return url, err
+}
+
+// Back navigates one step back in history
+func (s *Session) Back(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/back", struct{}{}, nil)
+}
+
+// Forward navigates one step forward in history
+func (s *Session) Forward(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/forward", struct{}{}, nil)
+}
+
+// Refresh reloads the current page
+func (s *Session) Refresh(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/refresh", struct{}{}, nil)
+}
+
+// Title returns the document title
+func (s *Session) Title(ctx context.Context) (string, error) {
+	var title string
+	err := s.do(ctx, http.MethodGet, "/title", nil, &title)
+	return title, err
+}
+
+// PageSource returns the serialized DOM of the current page
+func (s *Session) PageSource(ctx context.Context) (string, error) {
+	var source string
+	err := s.do(ctx, http.MethodGet, "/source", nil, &source)
+	return source, err
+}
+
+// WindowHandle returns the handle of the current window
+func (s *Session) WindowHandle(ctx context.Context) (string, error) {
+	var handle string
+	err := s.do(ctx, http.MethodGet, "/window", nil, &handle)
+	return handle, err
+}
+
+// WindowHandles returns the handles of all open windows
+func (s *Session) WindowHandles(ctx context.Context) ([]string, error) {
+	var handles []string
+	err := s.do(ctx, http.MethodGet, "/window/handles", nil, &handles)
+	return handles, err
+}
+
+// CloseWindow closes the current window and returns the remaining handles
+func (s *Session) CloseWindow(ctx context.Context) ([]string, error) {
+	var handles []string
+	err := s.do(ctx, http.MethodDelete, "/window", nil, &handles)
+	return handles, err
+}
+
+// SwitchToWindow makes the window with the handle current
+func (s *Session) SwitchToWindow(ctx context.Context, handle string) error {
+	return s.do(ctx, http.MethodPost, "/window", map[string]string{"handle": handle}, nil)
+}
+
+// NewWindow opens a new tab or window and returns its handle
+func (s *Session) NewWindow(ctx context.Context, windowType string) (string, error) {
+	var result struct {
+		Handle string `json:"handle"`
+		Type   string `json:"type"`
+	}
+	err := s.do(ctx, http.MethodPost, "/window/new", map[string]string{"type": windowType}, &result)
+	return result.Handle, err
+}
+
+// WindowRect returns the position and size of the current window
+func (s *Session) WindowRect(ctx context.Context) (*Rect, error) {
+	var rect Rect
+	if err := s.do(ctx, http.MethodGet, "/window/rect", nil, &rect); err != nil {
+		return nil, err
+	}
+	return &rect, nil
+}
+
+// SetWindowRect moves and resizes the current window
+func (s *Session) SetWindowRect(ctx context.Context, rect Rect) (*Rect, error) {
+	var result Rect
+	if err := s.do(ctx, http.MethodPost, "/window/rect", rect, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MaximizeWindow maximizes the current window
+func (s *Session) MaximizeWindow(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/window/maximize", struct{}{}, nil)
+}
+
+// MinimizeWindow minimizes the current window
+func (s *Session) MinimizeWindow(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/window/minimize", struct{}{}, nil)
+}
+
+// FullscreenWindow makes the current window fullscreen
+func (s *Session) FullscreenWindow(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/window/fullscreen", struct{}{}, nil)
+}
+
+// SwitchToFrame switches to a child frame given by element, index, or nil for the top-level context
+func (s *Session) SwitchToFrame(ctx context.Context, id interface{}) error {
+	return s.do(ctx, http.MethodPost, "/frame", map[string]interface{}{"id": id}, nil)
+}
+
+// SwitchToParentFrame switches to the parent of the current frame
+func (s *Session) SwitchToParentFrame(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/frame/parent", struct{}{}, nil)
+}
+
+// FindElement locates the first element in the document matching the selector
+func (s *Session) FindElement(ctx context.Context, using, value string) (*Element, error) {
+	var ref elementRef
+	if err := s.do(ctx, http.MethodPost, "/element", locator{using, value}, &ref); err != nil {
+		return nil, err
+	}
+	return s.element(ref), nil
+}
+
+// FindElements locates all elements in the document matching the selector
+func (s *Session) FindElements(ctx context.Context, using, value string) ([]*Element, error) {
+	var refs []elementRef
+	if err := s.do(ctx, http.MethodPost, "/elements", locator{using, value}, &refs); err != nil {
+		return nil, err
+	}
+	return s.elements(refs), nil
+}
+
+// ActiveElement returns the element that currently has focus
+func (s *Session) ActiveElement(ctx context.Context) (*Element, error) {
+	var ref elementRef
+	if err := s.do(ctx, http.MethodGet, "/element/active", nil, &ref); err != nil {
+		return nil, err
+	}
+	return s.element(ref), nil
+}
+
+// ExecuteScript runs a synchronous script and decodes its return value into result
+func (s *Session) ExecuteScript(ctx context.Context, script string, args []interface{}, result interface{}) error {
+	return s.execute(ctx, "/execute/sync", script, args, result)
+}
+
+// ExecuteAsyncScript runs a script that signals completion through its last argument
+func (s *Session) ExecuteAsyncScript(ctx context.Context, script string, args []interface{}, result interface{}) error {
+	return s.execute(ctx, "/execute/async", script, args, result)
+}
+
+func (s *Session) execute(ctx context.Context, path, script string, args []interface{}, result interface{}) error {
+	if args == nil {
+		args = []interface{}{}
+	}
+	payload := map[string]interface{}{"script": script, "args": args}
+	if result == nil {
+		var discard json.RawMessage
+		result = &discard
+	}
+	return s.do(ctx, http.MethodPost, path, payload, result)
+}
+
+// Cookies returns all cookies visible to the current page
+func (s *Session) Cookies(ctx context.Context) ([]Cookie, error) {
+	var cookies []Cookie
+	err := s.do(ctx, http.MethodGet, "/cookie", nil, &cookies)
+	return cookies, err
+}
+
+// Cookie returns the named cookie
+func (s *Session) Cookie(ctx context.Context, name string) (*Cookie, error) {
+	var cookie Cookie
+	if err := s.do(ctx, http.MethodGet, "/cookie/"+url.PathEscape(name), nil, &cookie); err != nil {
+		return nil, err
+	}
+	return &cookie, nil
+}
+
+// AddCookie sets a cookie for the current page
+func (s *Session) AddCookie(ctx context.Context, cookie Cookie) error {
+	return s.do(ctx, http.MethodPost, "/cookie", map[string]Cookie{"cookie": cookie}, nil)
+}
+
+// DeleteCookie removes the named cookie
+func (s *Session) DeleteCookie(ctx context.Context, name string) error {
+	return s.do(ctx, http.MethodDelete, "/cookie/"+url.PathEscape(name), nil, nil)
+}
+
+// DeleteAllCookies removes all cookies visible to the current page
+func (s *Session) DeleteAllCookies(ctx context.Context) error {
+	return s.do(ctx, http.MethodDelete, "/cookie", nil, nil)
+}
+
+// PerformActions dispatches the input action sequences
+func (s *Session) PerformActions(ctx context.Context, actions []ActionSequence) error {
+	return s.do(ctx, http.MethodPost, "/actions", map[string][]ActionSequence{"actions": actions}, nil)
+}
+
+// ReleaseActions releases all keys and pointer buttons that are currently held
+func (s *Session) ReleaseActions(ctx context.Context) error {
+	return s.do(ctx, http.MethodDelete, "/actions", nil, nil)
+}
+
+// DismissAlert dismisses the open user prompt
+func (s *Session) DismissAlert(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/alert/dismiss", struct{}{}, nil)
+}
+
+// AcceptAlert accepts the open user prompt
+func (s *Session) AcceptAlert(ctx context.Context) error {
+	return s.do(ctx, http.MethodPost, "/alert/accept", struct{}{}, nil)
+}
+
+// AlertText returns the message of the open user prompt
+func (s *Session) AlertText(ctx context.Context) (string, error) {
+	var text string
+	err := s.do(ctx, http.MethodGet, "/alert/text", nil, &text)
+	return text, err
+}
+
+// SendAlertText fills the text field of the open prompt
+func (s *Session) SendAlertText(ctx context.Context, text string) error {
+	return s.do(ctx, http.MethodPost, "/alert/text", map[string]string{"text": text}, nil)
+}
+
+// Screenshot captures the viewport of the current page as PNG
+func (s *Session) Screenshot(ctx context.Context) ([]byte, error) {
+	return s.screenshot(ctx, "/screenshot")
+}
+
+// FullPageScreenshot captures the whole document as PNG using the Geckodriver extension command.
+// Drivers without the extension fail with ErrUnknownCommand.
+func (s *Session) FullPageScreenshot(ctx context.Context) ([]byte, error) {
+	return s.screenshot(ctx, "/moz/screenshot/full")
+}
+
+func (s *Session) screenshot(ctx context.Context, path string) ([]byte, error) {
+	var encoded string
+	if err := s.do(ctx, http.MethodGet, path, nil, &encoded); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	return data, nil
+}
+
+// Print renders the current page as PDF
+func (s *Session) Print(ctx context.Context, opts PrintOptions) ([]byte, error) {
+	var encoded string
+	if err := s.do(ctx, http.MethodPost, "/print", opts, &encoded); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PDF: %w", err)
+	}
+	return data, nil
+}
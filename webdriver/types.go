@@ -0,0 +1,125 @@
+package webdriver
+
+import "encoding/json"
+
+// Status is the readiness state of the remote end
+type Status struct {
+	Ready   bool   `json:"ready"`
+	Message string `json:"message"`
+}
+
+// Capabilities describes the features requested for a new session.
+// Vendor specific entries such as moz:firefoxOptions go into Extensions.
+// WebSocketURL asks for a BiDi connection, opened with Session.ConnectBiDi.
+type Capabilities struct {
+	BrowserName             string
+	BrowserVersion          string
+	PlatformName            string
+	AcceptInsecureCerts     bool
+	PageLoadStrategy        string
+	UnhandledPromptBehavior string
+	Timeouts                *Timeouts
+	WebSocketURL            bool
+	Extensions              map[string]interface{}
+}
+
+// MarshalJSON flattens the standard capabilities and extensions into one object
+func (c Capabilities) MarshalJSON() ([]byte, error) {
+	caps := make(map[string]interface{}, len(c.Extensions)+8)
+	for name, value := range c.Extensions {
+		caps[name] = value
+	}
+	if c.BrowserName != "" {
+		caps["browserName"] = c.BrowserName
+	}
+	if c.BrowserVersion != "" {
+		caps["browserVersion"] = c.BrowserVersion
+	}
+	if c.PlatformName != "" {
+		caps["platformName"] = c.PlatformName
+	}
+	if c.AcceptInsecureCerts {
+		caps["acceptInsecureCerts"] = true
+	}
+	if c.PageLoadStrategy != "" {
+		caps["pageLoadStrategy"] = c.PageLoadStrategy
+	}
+	if c.UnhandledPromptBehavior != "" {
+		caps["unhandledPromptBehavior"] = c.UnhandledPromptBehavior
+	}
+	if c.Timeouts != nil {
+		caps["timeouts"] = c.Timeouts
+	}
+	if c.WebSocketURL {
+		caps["webSocketUrl"] = true
+	}
+	return json.Marshal(caps)
+}
+
+// Timeouts are the session timeouts in milliseconds
+type Timeouts struct {
+	Script   *int `json:"script,omitempty"`
+	PageLoad int  `json:"pageLoad,omitempty"`
+	Implicit int  `json:"implicit,omitempty"`
+}
+
+// Rect is the position and size of a window or element
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Cookie is a cookie as serialized by the WebDriver protocol
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Expiry   int64  `json:"expiry,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+}
+
+// PrintOptions are the parameters of the print command, lengths are in centimeters
+type PrintOptions struct {
+	// Orientation is "portrait" or "landscape"
+	Orientation string       `json:"orientation,omitempty"`
+	Scale       float64      `json:"scale,omitempty"`
+	Background  bool         `json:"background,omitempty"`
+	Page        *PrintPage   `json:"page,omitempty"`
+	Margin      *PrintMargin `json:"margin,omitempty"`
+	// PageRanges holds page numbers and ranges such as "2-4"
+	PageRanges []interface{} `json:"pageRanges,omitempty"`
+}
+
+// PrintPage is the paper size of the print command
+type PrintPage struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// PrintMargin are the page margins of the print command
+type PrintMargin struct {
+	Top    float64 `json:"top"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+	Right  float64 `json:"right"`
+}
+
+// Window types accepted by NewWindow
+const (
+	WindowTypeTab    = "tab"
+	WindowTypeWindow = "window"
+)
+
+// Element location strategies
+const (
+	ByCSSSelector     = "css selector"
+	ByLinkText        = "link text"
+	ByPartialLinkText = "partial link text"
+	ByTagName         = "tag name"
+	ByXPath           = "xpath"
+)